@@ -0,0 +1,303 @@
+package gridspech
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+// Assignment maps coordinates to the colors they are assigned. Unlike a TileSet,
+// an Assignment can never give a single coordinate more than one color.
+type Assignment struct {
+	colors map[TileCoord]TileColor
+}
+
+// ConflictError is returned when two colors are assigned to the same coordinate.
+type ConflictError struct {
+	Coord  TileCoord
+	Colors [2]TileColor
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("tile %v cannot have both color %d and color %d", e.Coord, e.Colors[0], e.Colors[1])
+}
+
+// NewAssignment returns an Assignment of each tile's coordinate to its color. It returns
+// a *ConflictError if two of the tiles have the same coordinate but different colors.
+func NewAssignment(tiles ...Tile) (Assignment, error) {
+	var a Assignment
+	for _, tile := range tiles {
+		if err := a.Set(tile.Coord, tile.Data.Color); err != nil {
+			return Assignment{}, err
+		}
+	}
+	return a, nil
+}
+
+// AssignmentOf is like NewAssignment, but for the tiles in ts.
+func AssignmentOf(ts TileSet) (Assignment, error) {
+	return NewAssignment(ts.Slice()...)
+}
+
+func (a *Assignment) checkInit() {
+	if a.colors == nil {
+		a.colors = make(map[TileCoord]TileColor)
+	}
+}
+
+// Set assigns color to coord. If coord has already been assigned a different
+// color, a is not changed and a *ConflictError is returned.
+func (a *Assignment) Set(coord TileCoord, color TileColor) error {
+	a.checkInit()
+	if existing, ok := a.colors[coord]; ok && existing != color {
+		return &ConflictError{Coord: coord, Colors: [2]TileColor{existing, color}}
+	}
+	a.colors[coord] = color
+	return nil
+}
+
+// Get returns the color assigned to coord, and whether coord has been assigned a color.
+func (a Assignment) Get(coord TileCoord) (TileColor, bool) {
+	color, ok := a.colors[coord]
+	return color, ok
+}
+
+// Has returns if coord has been assigned a color.
+func (a Assignment) Has(coord TileCoord) bool {
+	_, ok := a.colors[coord]
+	return ok
+}
+
+// Len returns the number of coordinates which have been assigned a color.
+func (a Assignment) Len() int {
+	return len(a.colors)
+}
+
+// Compatible returns true if a and other do not assign different colors to the same coordinate.
+func (a Assignment) Compatible(other Assignment) bool {
+	small, large := a, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	for coord, color := range small.colors {
+		if otherColor, ok := large.colors[coord]; ok && otherColor != color {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge assigns each color in other to a. If a and other are not compatible, a is
+// not changed and a *ConflictError is returned.
+func (a *Assignment) Merge(other Assignment) error {
+	for _, coord := range other.Coords() {
+		if existing, ok := a.colors[coord]; ok && existing != other.colors[coord] {
+			return &ConflictError{Coord: coord, Colors: [2]TileColor{existing, other.colors[coord]}}
+		}
+	}
+	a.checkInit()
+	for coord, color := range other.colors {
+		a.colors[coord] = color
+	}
+	return nil
+}
+
+// Merged returns a new Assignment containing the colors from both a and other, or
+// false if they are not compatible.
+func (a Assignment) Merged(other Assignment) (Assignment, bool) {
+	if !a.Compatible(other) {
+		return Assignment{}, false
+	}
+	merged := a.Clone()
+	merged.Merge(other)
+	return merged, true
+}
+
+// Clone returns a copy of a.
+func (a Assignment) Clone() Assignment {
+	var clone Assignment
+	clone.checkInit()
+	for coord, color := range a.colors {
+		clone.colors[coord] = color
+	}
+	return clone
+}
+
+// Apply sets the color of each tile in g which a assigns a color to.
+func (a Assignment) Apply(g Grid) {
+	for coord, color := range a.colors {
+		g.Tiles[coord.X][coord.Y].Data.Color = color
+	}
+}
+
+// Eq returns if a assigns exactly the same colors to exactly the same coordinates as other.
+func (a Assignment) Eq(other Assignment) bool {
+	if a.Len() != other.Len() {
+		return false
+	}
+	for coord, color := range a.colors {
+		if otherColor, ok := other.colors[coord]; !ok || otherColor != color {
+			return false
+		}
+	}
+	return true
+}
+
+// Coords returns the coordinates which have been assigned a color, sorted by X and then Y.
+func (a Assignment) Coords() []TileCoord {
+	coords := make([]TileCoord, 0, len(a.colors))
+	for coord := range a.colors {
+		coords = append(coords, coord)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].X != coords[j].X {
+			return coords[i].X < coords[j].X
+		}
+		return coords[i].Y < coords[j].Y
+	})
+	return coords
+}
+
+// Compare orders assignments lexicographically by their colors in reading order: from the top
+// row of the grid to the bottom, and from left to right within each row. A coordinate which is
+// not assigned a color comes before any color. Compare returns -1 if a comes before other, 1 if
+// a comes after other, and 0 if they are equal.
+func (a Assignment) Compare(other Assignment) int {
+	coords := make([]TileCoord, 0, a.Len()+other.Len())
+	for coord := range a.colors {
+		coords = append(coords, coord)
+	}
+	for coord := range other.colors {
+		if !a.Has(coord) {
+			coords = append(coords, coord)
+		}
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].Y != coords[j].Y {
+			return coords[i].Y > coords[j].Y
+		}
+		return coords[i].X < coords[j].X
+	})
+
+	for _, coord := range coords {
+		color, ok := a.colors[coord]
+		otherColor, otherOk := other.colors[coord]
+		switch {
+		case !ok:
+			return -1
+		case !otherOk:
+			return 1
+		case color < otherColor:
+			return -1
+		case color > otherColor:
+			return 1
+		}
+	}
+	return 0
+}
+
+// SortAssignments sorts assignments into the order defined by Assignment.Compare.
+func SortAssignments(assignments []Assignment) {
+	sort.Slice(assignments, func(i, j int) bool {
+		return assignments[i].Compare(assignments[j]) < 0
+	})
+}
+
+// ToTileCoordSet returns the coordinates which have been assigned a color.
+func (a Assignment) ToTileCoordSet() TileCoordSet {
+	var result TileCoordSet
+	for coord := range a.colors {
+		result.Add(coord)
+	}
+	return result
+}
+
+// Tiles returns the tiles of g which a assigns a color to, with their assigned colors.
+func (a Assignment) Tiles(g Grid) TileSet {
+	var ts TileSet
+	for coord, color := range a.colors {
+		tile := *g.TileAtCoord(coord)
+		tile.Data.Color = color
+		ts.Add(tile)
+	}
+	return ts
+}
+
+// Key returns a canonical string representation of a. Two assignments have the same
+// key exactly when they are equal, so it can be used as a map key.
+func (a Assignment) Key() string {
+	var sb strings.Builder
+	for _, coord := range a.Coords() {
+		fmt.Fprintf(&sb, "%d,%d=%d;", coord.X, coord.Y, a.colors[coord])
+	}
+	return sb.String()
+}
+
+// Fingerprint is a 128-bit hash which identifies an Assignment.
+type Fingerprint [16]byte
+
+// Fingerprint returns a hash of the coordinates and colors in a. It does not depend on the order
+// that colors were assigned in, and is the same between runs of the program, so it can be used to
+// recognize the same assignment later. Equal assignments always have the same fingerprint, and
+// different assignments are all but certain to have different fingerprints.
+func (a Assignment) Fingerprint() Fingerprint {
+	h := fnv.New128a()
+	var buf [2*binary.MaxVarintLen64 + 1]byte
+	for _, coord := range a.Coords() {
+		n := binary.PutVarint(buf[:], int64(coord.X))
+		n += binary.PutVarint(buf[n:], int64(coord.Y))
+		buf[n] = byte(a.colors[coord])
+		h.Write(buf[:n+1])
+	}
+
+	var fp Fingerprint
+	h.Sum(fp[:0])
+	return fp
+}
+
+func (fp Fingerprint) String() string {
+	return hex.EncodeToString(fp[:])
+}
+
+// String returns the colors in a laid out like the grid, with spaces for coordinates
+// which are not assigned a color, like TileSet.String.
+func (a Assignment) String() string {
+	var maxX, maxY int
+	for coord := range a.colors {
+		if coord.X > maxX {
+			maxX = coord.X
+		}
+		if coord.Y > maxY {
+			maxY = coord.Y
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for y := maxY; y >= 0 && len(a.colors) > 0; y-- {
+		for x := 0; x <= maxX; x++ {
+			if color, ok := a.colors[TileCoord{X: x, Y: y}]; ok {
+				sb.WriteByte(byte(color) + '0')
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		if y > 0 {
+			sb.WriteByte('|')
+		}
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// MultiLineString returns a string representation of a on multiple lines.
+func (a Assignment) MultiLineString() string {
+	next := a.String()
+	next = next[1 : len(next)-1]
+	next = strings.ReplaceAll(next, "|", "\n")
+	next += "\n"
+	return next
+}
@@ -0,0 +1,157 @@
+package gridspech_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+func TestAssignmentSet(t *testing.T) {
+	var a gs.Assignment
+	coord := gs.TileCoord{X: 1, Y: 2}
+	if err := a.Set(coord, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Set(coord, 1); err != nil {
+		t.Errorf("expected setting the same color again to succeed, got %v", err)
+	}
+
+	err := a.Set(coord, 2)
+	var conflict *gs.ConflictError
+	if !errors.As(err, &conflict) || conflict.Coord != coord || conflict.Colors != [2]gs.TileColor{1, 2} {
+		t.Errorf("expected conflict at %v between 1 and 2, got %v", coord, err)
+	}
+	if color, ok := a.Get(coord); !ok || color != 1 {
+		t.Errorf("expected color to still be 1, got %v, %v", color, ok)
+	}
+}
+
+func TestAssignmentMerge(t *testing.T) {
+	var a, b, c gs.Assignment
+	a.Set(gs.TileCoord{X: 0, Y: 0}, 1)
+	a.Set(gs.TileCoord{X: 1, Y: 0}, 0)
+	b.Set(gs.TileCoord{X: 1, Y: 0}, 0)
+	b.Set(gs.TileCoord{X: 2, Y: 0}, 2)
+	c.Set(gs.TileCoord{X: 2, Y: 0}, 1)
+
+	if !a.Compatible(b) || !a.Compatible(c) || b.Compatible(c) {
+		t.Errorf("expected only b and c to conflict")
+	}
+
+	if err := b.Merge(c); err == nil {
+		t.Errorf("expected b and c to conflict")
+	}
+	if b.Len() != 2 {
+		t.Errorf("expected failed merge to leave b unchanged, got %v", b)
+	}
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if a.String() != "{102}" {
+		t.Errorf("expected {102}, got %v", a)
+	}
+	if _, ok := a.Merged(c); ok {
+		t.Errorf("expected a and c to conflict after merging b")
+	}
+}
+
+func TestAssignmentEq(t *testing.T) {
+	a, _ := gs.NewAssignment(gs.Tile{Coord: gs.TileCoord{X: 0, Y: 1}, Data: gs.TileData{Color: 1}}, gs.Tile{Coord: gs.TileCoord{X: 1, Y: 0}})
+	b, _ := gs.NewAssignment(gs.Tile{Coord: gs.TileCoord{X: 1, Y: 0}}, gs.Tile{Coord: gs.TileCoord{X: 0, Y: 1}, Data: gs.TileData{Color: 1}})
+	if !a.Eq(b) || a.Key() != b.Key() {
+		t.Errorf("expected %v and %v to be equal with equal keys", a, b)
+	}
+
+	b.Set(gs.TileCoord{X: 1, Y: 1}, 0)
+	if a.Eq(b) || a.Key() == b.Key() {
+		t.Errorf("expected %v and %v to not be equal", a, b)
+	}
+}
+
+func TestAssignmentOf(t *testing.T) {
+	ts := gs.NewTileSet(
+		gs.Tile{Coord: gs.TileCoord{X: 0, Y: 0}, Data: gs.TileData{Color: 1}},
+		gs.Tile{Coord: gs.TileCoord{X: 0, Y: 0}, Data: gs.TileData{Color: 2}},
+	)
+	if _, err := gs.AssignmentOf(ts); err == nil {
+		t.Errorf("expected a tileset with two colors for one tile to conflict")
+	}
+}
+
+func TestAssignmentApply(t *testing.T) {
+	grid := gs.MakeGridFromString("0  0e  0m1", 3)
+	var a gs.Assignment
+	a.Set(gs.TileCoord{X: 0, Y: 0}, 2)
+	a.Set(gs.TileCoord{X: 1, Y: 0}, 1)
+	a.Apply(grid)
+
+	if grid.TileAt(0, 0).Data.Color != 2 || grid.TileAt(1, 0).Data.Color != 1 || grid.TileAt(2, 0).Data.Color != 0 {
+		t.Errorf("expected colors to be applied, got\n%v", grid)
+	}
+	if grid.TileAt(1, 0).Data.Type != gs.TypeGoal {
+		t.Errorf("expected tile types to be unchanged, got\n%v", grid)
+	}
+	if tiles := a.Tiles(grid); tiles.Len() != 2 || !tiles.Has(*grid.TileAt(1, 0)) {
+		t.Errorf("expected tiles to contain the applied tiles, got %v", tiles)
+	}
+}
+
+func TestAssignmentFingerprint(t *testing.T) {
+	var a, b gs.Assignment
+	a.Set(gs.TileCoord{X: 0, Y: 1}, 1)
+	a.Set(gs.TileCoord{X: 1, Y: 0}, 2)
+	b.Set(gs.TileCoord{X: 1, Y: 0}, 2)
+	b.Set(gs.TileCoord{X: 0, Y: 1}, 1)
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("expected fingerprint to not depend on order, got %v and %v", a.Fingerprint(), b.Fingerprint())
+	}
+
+	// the fingerprint must be the same between runs
+	const expected = "f367bb1a0c3c64bf6dc69d97383dd5de"
+	if a.Fingerprint().String() != expected {
+		t.Errorf("expected fingerprint %v, got %v", expected, a.Fingerprint())
+	}
+
+	var swapped, extra gs.Assignment
+	swapped.Set(gs.TileCoord{X: 0, Y: 1}, 2)
+	swapped.Set(gs.TileCoord{X: 1, Y: 0}, 1)
+	extra = a.Clone()
+	extra.Set(gs.TileCoord{X: 5, Y: 5}, 0)
+	for _, other := range []gs.Assignment{{}, swapped, extra} {
+		if a.Fingerprint() == other.Fingerprint() {
+			t.Errorf("expected %v and %v to have different fingerprints", a, other)
+		}
+	}
+}
+
+func TestAssignmentCompare(t *testing.T) {
+	var solutions []gs.Assignment
+	for _, str := range []string{"11|00", "00|11", "01|10", "10|01", "00|10", "  |10", "0 |00"} {
+		var a gs.Assignment
+		rows := strings.Split(str, "|")
+		for i, row := range rows {
+			for x, c := range row {
+				if c != ' ' {
+					a.Set(gs.TileCoord{X: x, Y: len(rows) - 1 - i}, gs.TileColor(c-'0'))
+				}
+			}
+		}
+		solutions = append(solutions, a)
+	}
+	gs.SortAssignments(solutions)
+	var sorted []string
+	for _, a := range solutions {
+		sorted = append(sorted, a.String())
+	}
+	expected := "{10} {0 |00} {00|10} {00|11} {01|10} {10|01} {11|00}"
+	if actual := strings.Join(sorted, " "); actual != expected {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	if solutions[2].Compare(solutions[2].Clone()) != 0 {
+		t.Errorf("expected %v to be equal to itself", solutions[2])
+	}
+}
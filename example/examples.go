@@ -1,6 +1,7 @@
 package example
 
 import (
+	"context"
 	"fmt"
 	"sort"
 
@@ -83,10 +84,13 @@ func FindSolutionsForALevels() {
 
 // FindSolution returns a new grid as a solution to `grid`
 func FindSolution(grid gridspech.Grid) gridspech.Grid {
-	ch := solve.NewGridSolver(grid).SolveGoals()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := solve.NewGridSolver(grid).SolveGoalsContext(ctx)
 	newGrid := grid.Clone()
 	firstSolution := <-ch
-	newGrid.ApplyTileSet(firstSolution)
+	firstSolution.Apply(newGrid)
 
 	return newGrid
 }
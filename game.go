@@ -1,36 +1,34 @@
 package gridspech
 
-//go:generate stringer -type=TileType -linecomment -output=stringers.go
-
 const (
 	// TypeHole represents a tile which does not exist. They cannot have Color.
-	TypeHole TileType = iota // _
+	TypeHole TileType = iota
 
 	// TypeBlank is a tile which does not have any icons.
-	TypeBlank // Blank
+	TypeBlank
 
 	// TypeGoal is a tile which must have a direct path to another goal.
-	TypeGoal // Goal
+	TypeGoal
 
 	// TypeCrown tiles must touch all tiles of their state.
 	// If there are multiple crowns on the same state, they must not not touch each other, and
 	// together they must touch all tiles of their state.
-	TypeCrown // Crown
+	TypeCrown
 
 	// TypeDot1 must be touching exactly 1 tiles with Color >= 1.
-	TypeDot1 // Dot1
+	TypeDot1
 
 	// TypeDot2 must be touching exactly 2 tiles with Color >= 1.
-	TypeDot2 // Dot2
+	TypeDot2
 
 	// TypeDot3 must be touching exactly 3 tiles with Color >= 1.
-	TypeDot3 // Dot3
+	TypeDot3
 
 	// TypeJoin1 must touch exactly 1 tile with a non-blank type.
-	TypeJoin1 // Join1
+	TypeJoin1
 
 	// TypeJoin2 must touch exactly 1 tile with a non-blank type.
-	TypeJoin2 // Join2
+	TypeJoin2
 )
 
 // Constants for TileColor
@@ -44,6 +42,7 @@ const (
 type TileColor byte
 
 // TileType represents what kind of tile it is, ie "what icon to display on it".
+// The behavior of each TileType is described by its TileRule.
 type TileType byte
 
 // Grid represents a single level of gridspech.
@@ -295,26 +295,11 @@ func (td TileData) String() string {
 	if td.Sticky {
 		sb.WriteByte('/')
 	}
-	switch td.Type {
-	case TypeBlank:
-		break
-	case TypeCrown:
-		sb.WriteByte('k')
-	case TypeGoal:
-		sb.WriteByte('e')
-	case TypeDot1:
-		sb.WriteString("m1")
-	case TypeDot2:
-		sb.WriteString("m2")
-	case TypeDot3:
-		sb.WriteString("m3")
-	case TypeJoin1:
-		sb.WriteString("j1")
-	case TypeJoin2:
-		sb.WriteString("j2")
-	default:
+	rule := td.Type.Rule()
+	if rule == nil {
 		panic(fmt.Sprintf("invalid type %d", td.Type))
 	}
+	sb.WriteString(rule.Token())
 
 	if td.ArrowWest {
 		sb.WriteByte('<')
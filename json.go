@@ -0,0 +1,218 @@
+package gridspech
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// JSONVersion is the version of the JSON schema written by Grid.MarshalJSON.
+//
+// The schema for version 1 is as follows. Fields marked with `omitempty` are left out
+// when they have their zero value.
+//
+//	Grid {
+//	    "version":   1,
+//	    "maxColors": int,
+//	    "width":     int,
+//	    "height":    int,
+//	    "tiles":     [[TileData]] // indexed as tiles[x][y], where y=0 is the bottom row
+//	}
+//	TileData {
+//	    "type":   string,   // the TileType's name, ie "Dot2", or "_" for holes
+//	    "color":  int,      // omitempty
+//	    "sticky": bool,     // omitempty
+//	    "arrows": [string]  // omitempty, any of "north", "east", "south", "west"
+//	}
+//	TileCoord { "x": int, "y": int }
+//	Tile      { "coord": TileCoord, "data": TileData }
+//	TileSet   [Tile] // sorted by x, and then y
+const JSONVersion = 1
+
+// MarshalText implements encoding.TextMarshaler.
+func (typ TileType) MarshalText() ([]byte, error) {
+	if typ.Rule() == nil {
+		return nil, fmt.Errorf("gridspech: invalid tile type %d", typ)
+	}
+	return []byte(typ.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (typ *TileType) UnmarshalText(text []byte) error {
+	for i, rule := range tileRules {
+		if rule.Name() == string(text) {
+			*typ = TileType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("gridspech: unknown tile type %q", text)
+}
+
+type tileDataJSON struct {
+	Type   TileType  `json:"type"`
+	Color  TileColor `json:"color,omitempty"`
+	Sticky bool      `json:"sticky,omitempty"`
+	Arrows []string  `json:"arrows,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (td TileData) MarshalJSON() ([]byte, error) {
+	tdj := tileDataJSON{Type: td.Type, Color: td.Color, Sticky: td.Sticky}
+	if td.ArrowNorth {
+		tdj.Arrows = append(tdj.Arrows, "north")
+	}
+	if td.ArrowEast {
+		tdj.Arrows = append(tdj.Arrows, "east")
+	}
+	if td.ArrowSouth {
+		tdj.Arrows = append(tdj.Arrows, "south")
+	}
+	if td.ArrowWest {
+		tdj.Arrows = append(tdj.Arrows, "west")
+	}
+	return json.Marshal(tdj)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (td *TileData) UnmarshalJSON(b []byte) error {
+	var tdj tileDataJSON
+	if err := json.Unmarshal(b, &tdj); err != nil {
+		return err
+	}
+
+	data := TileData{Type: tdj.Type, Color: tdj.Color, Sticky: tdj.Sticky}
+	for _, arrow := range tdj.Arrows {
+		switch arrow {
+		case "north":
+			data.ArrowNorth = true
+		case "east":
+			data.ArrowEast = true
+		case "south":
+			data.ArrowSouth = true
+		case "west":
+			data.ArrowWest = true
+		default:
+			return fmt.Errorf("gridspech: unknown arrow %q", arrow)
+		}
+	}
+	*td = data
+	return nil
+}
+
+type tileCoordJSON struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t TileCoord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tileCoordJSON(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *TileCoord) UnmarshalJSON(b []byte) error {
+	var tcj tileCoordJSON
+	if err := json.Unmarshal(b, &tcj); err != nil {
+		return err
+	}
+	*t = TileCoord(tcj)
+	return nil
+}
+
+type tileJSON struct {
+	Coord TileCoord `json:"coord"`
+	Data  TileData  `json:"data"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Tile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tileJSON(t))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Tile) UnmarshalJSON(b []byte) error {
+	var tj tileJSON
+	if err := json.Unmarshal(b, &tj); err != nil {
+		return err
+	}
+	*t = Tile(tj)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (ts TileSet) MarshalJSON() ([]byte, error) {
+	tiles := ts.Slice()
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i].Coord.X != tiles[j].Coord.X {
+			return tiles[i].Coord.X < tiles[j].Coord.X
+		}
+		return tiles[i].Coord.Y < tiles[j].Coord.Y
+	})
+	return json.Marshal(tiles)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (ts *TileSet) UnmarshalJSON(b []byte) error {
+	var tiles []Tile
+	if err := json.Unmarshal(b, &tiles); err != nil {
+		return err
+	}
+	*ts = NewTileSet(tiles...)
+	return nil
+}
+
+type gridJSON struct {
+	Version   int          `json:"version"`
+	MaxColors int          `json:"maxColors"`
+	Width     int          `json:"width"`
+	Height    int          `json:"height"`
+	Tiles     [][]TileData `json:"tiles"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (g Grid) MarshalJSON() ([]byte, error) {
+	gj := gridJSON{
+		Version:   JSONVersion,
+		MaxColors: g.MaxColors,
+		Width:     g.Width(),
+		Height:    g.Height(),
+		Tiles:     make([][]TileData, g.Width()),
+	}
+	for x, col := range g.Tiles {
+		gj.Tiles[x] = make([]TileData, len(col))
+		for y, tile := range col {
+			gj.Tiles[x][y] = tile.Data
+		}
+	}
+	return json.Marshal(gj)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (g *Grid) UnmarshalJSON(b []byte) error {
+	var gj gridJSON
+	if err := json.Unmarshal(b, &gj); err != nil {
+		return err
+	}
+	if gj.Version != JSONVersion {
+		return fmt.Errorf("gridspech: unsupported grid version %d", gj.Version)
+	}
+	if gj.Width < 1 || gj.Height < 1 || len(gj.Tiles) != gj.Width {
+		return fmt.Errorf("gridspech: grid has %d columns, but its width is %d", len(gj.Tiles), gj.Width)
+	}
+
+	grid := Grid{MaxColors: gj.MaxColors, Tiles: make([][]Tile, gj.Width)}
+	for x, col := range gj.Tiles {
+		if len(col) != gj.Height {
+			return fmt.Errorf("gridspech: column %d has %d tiles, but the grid's height is %d", x, len(col), gj.Height)
+		}
+		grid.Tiles[x] = make([]Tile, gj.Height)
+		for y, data := range col {
+			if data.Type != TypeHole && int(data.Color) >= gj.MaxColors {
+				return fmt.Errorf("gridspech: tile %v has color %d, but maxColors is %d", TileCoord{X: x, Y: y}, data.Color, gj.MaxColors)
+			}
+			grid.Tiles[x][y] = Tile{Coord: TileCoord{X: x, Y: y}, Data: data}
+		}
+	}
+	*g = grid
+	return nil
+}
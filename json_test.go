@@ -0,0 +1,97 @@
+package gridspech_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+func TestGridJSON_roundTrip(t *testing.T) {
+	grid := gs.MakeGridFromString(`
+	0m3<^v>  _    1/e
+	2j2      0k   0
+	`, 3)
+
+	b, err := json.Marshal(grid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded gs.Grid
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.String() != grid.String() || decoded.MaxColors != grid.MaxColors {
+		t.Errorf("\nexpected:\n%v\ngot:\n%v", grid, decoded)
+	}
+	for x := range grid.Tiles {
+		for y := range grid.Tiles[x] {
+			if grid.Tiles[x][y] != decoded.Tiles[x][y] {
+				t.Errorf("expected %v, got %v", grid.Tiles[x][y], decoded.Tiles[x][y])
+			}
+		}
+	}
+}
+
+func TestTileDataJSON(t *testing.T) {
+	data := gs.TileData{Type: gs.TypeDot2, Color: 1, Sticky: true, ArrowNorth: true, ArrowWest: true}
+	const expected = `{"type":"Dot2","color":1,"sticky":true,"arrows":["north","west"]}`
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != expected {
+		t.Errorf("\nexpected: %s\ngot:      %s", expected, b)
+	}
+
+	var decoded gs.TileData
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded != data {
+		t.Errorf("\nexpected: %#v\ngot:      %#v", data, decoded)
+	}
+}
+
+func TestTileSetJSON(t *testing.T) {
+	ts := gs.NewTileSet(
+		gs.Tile{Coord: gs.TileCoord{X: 1, Y: 0}, Data: gs.TileData{Type: gs.TypeBlank, Color: 1}},
+		gs.Tile{Coord: gs.TileCoord{X: 0, Y: 2}, Data: gs.TileData{Type: gs.TypeGoal}},
+	)
+	const expected = `[{"coord":{"x":0,"y":2},"data":{"type":"Goal"}},{"coord":{"x":1,"y":0},"data":{"type":"Blank","color":1}}]`
+
+	b, err := json.Marshal(ts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != expected {
+		t.Errorf("\nexpected: %s\ngot:      %s", expected, b)
+	}
+
+	var decoded gs.TileSet
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.Eq(ts) {
+		t.Errorf("\nexpected: %v\ngot:      %v", ts, decoded)
+	}
+}
+
+func TestGridJSON_errors(t *testing.T) {
+	cases := map[string]string{
+		"version":      `{"version":2,"maxColors":2,"width":1,"height":1,"tiles":[[{"type":"Blank"}]]}`,
+		"unknown type": `{"version":1,"maxColors":2,"width":1,"height":1,"tiles":[[{"type":"Dot9"}]]}`,
+		"ragged":       `{"version":1,"maxColors":2,"width":2,"height":1,"tiles":[[{"type":"Blank"}],[]]}`,
+		"color":        `{"version":1,"maxColors":2,"width":1,"height":1,"tiles":[[{"type":"Blank","color":2}]]}`,
+		"arrow":        `{"version":1,"maxColors":2,"width":1,"height":1,"tiles":[[{"type":"Blank","arrows":["up"]}]]}`,
+	}
+	for name, input := range cases {
+		var grid gs.Grid
+		if err := json.NewDecoder(strings.NewReader(input)).Decode(&grid); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package gridspech
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// nativeWraps are the characters of the wrap fields of the game's tile format, in the order
+// that they appear, along with the arrow that each one sets.
+var nativeWraps = []struct {
+	char  byte
+	arrow func(data *TileData) *bool
+}{
+	{'<', func(data *TileData) *bool { return &data.ArrowWest }},
+	{'^', func(data *TileData) *bool { return &data.ArrowNorth }},
+	{'v', func(data *TileData) *bool { return &data.ArrowSouth }},
+	{'>', func(data *TileData) *bool { return &data.ArrowEast }},
+}
+
+// parseNativeTile parses a tile in the game's `${t.value}${lock}${sym}${wrapl}${wrapu}${wrapd}${wrapr}`
+// format. Unlike parseTileData, each field must be in that order. If the tile is malformed, a
+// description of the problem is returned.
+func parseNativeTile(tok string, maxColors int) (TileData, string) {
+	var data TileData
+	if tok == "_" {
+		data.Type = TypeHole
+		return data, ""
+	}
+
+	digits := len(tok) - len(strings.TrimLeft(tok, "0123456789"))
+	if digits == 0 {
+		return data, "tile must start with a value or be a hole"
+	}
+	value, err := strconv.Atoi(tok[:digits])
+	if err != nil || value >= maxColors {
+		return data, fmt.Sprintf("value must be less than %d", maxColors)
+	}
+	data.Color = TileColor(value)
+	rest := tok[digits:]
+
+	if strings.HasPrefix(rest, "/") {
+		data.Sticky = true
+		rest = rest[1:]
+	}
+
+	var symLen int
+	data.Type, symLen = typeForToken(rest)
+	rest = rest[symLen:]
+
+	for _, wrap := range nativeWraps {
+		if len(rest) > 0 && rest[0] == wrap.char {
+			*wrap.arrow(&data) = true
+			rest = rest[1:]
+		}
+	}
+	if rest != "" {
+		return data, fmt.Sprintf("unexpected %q after the fields of the tile", rest)
+	}
+	return data, ""
+}
+
+// ReadNative reads a level stored in the game's native encoding. Each line is a row of tiles
+// separated by whitespace, with the top row first. Each tile is encoded in the game's
+// `${t.value}${lock}${sym}${wrapl}${wrapu}${wrapd}${wrapr}` format, where:
+//   - value is the tile's color, ie "1".
+//   - lock is "/" if the tile is sticky, and empty otherwise.
+//   - sym is the token of the tile's type, ie "e" for goals or "m2" for Dot2, and empty for blank tiles.
+//   - wrapl, wrapu, wrapd and wrapr are "<", "^", "v" and ">" if the tile wraps
+//     west, north, south and east, and empty otherwise.
+//
+// Holes are written as "_". Unlike ParseGrid, the fields of each tile must be in the order
+// above, so "0e^v" is a goal which wraps north and south, but "0ev^" is an error.
+//
+// If the level is malformed, the returned error will be a *ParseError.
+func ReadNative(r io.Reader, maxColors int) (Grid, error) {
+	if maxColors < 1 {
+		return Grid{}, fmt.Errorf("gridspech: maxColors must be at least 1, got %d", maxColors)
+	}
+
+	var rows [][]TileData
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		tokens := splitRow(scanner.Text())
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(rows) > 0 && len(tokens) != len(rows[0]) {
+			return Grid{}, &ParseError{Line: lineNum, Msg: fmt.Sprintf("row has %d tiles, but the first row has %d", len(tokens), len(rows[0]))}
+		}
+
+		row := make([]TileData, len(tokens))
+		for i, tok := range tokens {
+			data, problem := parseNativeTile(tok.text, maxColors)
+			if problem != "" {
+				return Grid{}, &ParseError{Line: lineNum, Column: tok.column, Token: tok.text, Msg: problem}
+			}
+			row[i] = data
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return Grid{}, err
+	}
+	if len(rows) == 0 {
+		return Grid{}, &ParseError{Line: 1, Msg: "native level is empty"}
+	}
+
+	height, width := len(rows), len(rows[0])
+	grid := Grid{MaxColors: maxColors, Tiles: make([][]Tile, width)}
+	for x := range grid.Tiles {
+		grid.Tiles[x] = make([]Tile, height)
+		for y := range grid.Tiles[x] {
+			grid.Tiles[x][y] = Tile{Coord: TileCoord{X: x, Y: y}, Data: rows[height-y-1][x]}
+		}
+	}
+	return grid, nil
+}
+
+// WriteNative writes g to w in the format read by ReadNative. Tiles are padded so
+// that the columns line up.
+func WriteNative(w io.Writer, g Grid) error {
+	var longest int
+	tileStrs := make([][]string, g.Width())
+	for x, col := range g.Tiles {
+		tileStrs[x] = make([]string, len(col))
+		for y, tile := range col {
+			if tile.Data.Type.Rule() == nil {
+				return fmt.Errorf("gridspech: tile (%d, %d) has invalid type %d", x, y, tile.Data.Type)
+			}
+			tileStrs[x][y] = nativeTileString(tile.Data)
+			if len(tileStrs[x][y]) > longest {
+				longest = len(tileStrs[x][y])
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for y := g.Height() - 1; y >= 0; y-- {
+		for x := 0; x < g.Width(); x++ {
+			if x > 0 {
+				bw.WriteString("  ")
+			}
+			bw.WriteString(tileStrs[x][y] + strings.Repeat(" ", longest-len(tileStrs[x][y])))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// nativeTileString is like data.String, but values of 10 or more are written in full
+// instead of as a single character.
+func nativeTileString(data TileData) string {
+	str := data.String()
+	if data.Type == TypeHole {
+		return str
+	}
+	return strconv.Itoa(int(data.Color)) + str[1:]
+}
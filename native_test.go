@@ -0,0 +1,147 @@
+package gridspech_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// levels from the game, in the game's own tile format. They are the same
+// levels which are used by the upstream solver tests.
+var nativeLevels = map[string]string{
+	"A2": `
+1/e  _    0    0e
+0    0    0    _
+`,
+	"F10": `
+0    0    0    0    0    0
+0    0e   0k   0    0    0
+0    0    0k   0    0    0
+0    0    0k   0    0    0
+0    0    0k   0e   0    0
+0j1  0e   0    0    0j1  0e
+`,
+	"G4": `
+0m3<^v>  _        0<^v>    _        _        _        0<^v>    _
+0<^v>    0m2^v    0m1^v    0m3^v    0^v      0m2^v    0m3^v    0m1<^v>
+`,
+	"goal arrows": `
+_    0e   0^v  0e   0
+0    0<>  _    0<>  0
+0    0e   0^v  0e   _
+`,
+}
+
+func TestReadNative(t *testing.T) {
+	grid, err := gs.ReadNative(strings.NewReader(nativeLevels["G4"]), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		Actual, Expected gs.TileData
+	}{
+		{grid.TileAt(0, 1).Data, gs.TileData{Type: gs.TypeDot3, ArrowWest: true, ArrowNorth: true, ArrowSouth: true, ArrowEast: true}},
+		{grid.TileAt(1, 1).Data, gs.TileData{Type: gs.TypeHole}},
+		{grid.TileAt(1, 0).Data, gs.TileData{Type: gs.TypeDot2, ArrowNorth: true, ArrowSouth: true}},
+		{grid.TileAt(4, 0).Data, gs.TileData{Type: gs.TypeBlank, ArrowNorth: true, ArrowSouth: true}},
+	}
+	for _, testCase := range cases {
+		if testCase.Expected != testCase.Actual {
+			t.Errorf("\nexpected: %#v\ngot:      %#v\n", testCase.Expected, testCase.Actual)
+		}
+	}
+
+	grid, err = gs.ReadNative(strings.NewReader(nativeLevels["A2"]), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := gs.TileData{Type: gs.TypeGoal, Color: 1, Sticky: true}
+	if actual := grid.TileAt(0, 1).Data; actual != expected {
+		t.Errorf("\nexpected: %#v\ngot:      %#v\n", expected, actual)
+	}
+}
+
+func TestNative_roundTrip(t *testing.T) {
+	for name, level := range nativeLevels {
+		grid, err := gs.ReadNative(strings.NewReader(level), 2)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := gs.WriteNative(&buf, grid); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if trimLines(buf.String()) != trimLines(level) {
+			t.Errorf("%s: expected:\n%v\ngot:\n%v", name, level, buf.String())
+		}
+
+		// the game's format is also understood by ParseGrid
+		if parsed := gs.MakeGridFromString(level, 2); parsed.String() != grid.String() {
+			t.Errorf("%s: expected ParseGrid to read the same level, got:\n%v", name, parsed)
+		}
+	}
+}
+
+// trimLines removes the padding from the end of each line of s.
+func trimLines(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestNative_roundTripLargeValues(t *testing.T) {
+	const level = `
+10/e  _     0^v   11j1
+2     9m1<  10    0e
+`
+	grid, err := gs.ReadNative(strings.NewReader(level), 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := gs.TileData{Type: gs.TypeGoal, Color: 10, Sticky: true}
+	if actual := grid.TileAt(0, 1).Data; actual != expected {
+		t.Errorf("\nexpected: %#v\ngot:      %#v\n", expected, actual)
+	}
+
+	var buf bytes.Buffer
+	if err := gs.WriteNative(&buf, grid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trimLines(buf.String()) != trimLines(level) {
+		t.Errorf("expected:\n%v\ngot:\n%v", level, buf.String())
+	}
+	reread, err := gs.ReadNative(&buf, 12)
+	if err != nil {
+		t.Fatalf("unexpected error reading the written level: %v", err)
+	}
+	if reread.String() != grid.String() {
+		t.Errorf("expected:\n%v\ngot:\n%v", grid, reread)
+	}
+}
+
+func TestReadNative_errors(t *testing.T) {
+	cases := map[string]string{
+		"empty":     "\n\n",
+		"ragged":    "0  0\n0",
+		"symbol":    "0x",
+		"value":     "3",
+		"no value":  "e",
+		"order":     "0ev^",
+		"lock last": "0e/",
+		"twice":     "0^^",
+	}
+	for name, level := range cases {
+		_, err := gs.ReadNative(strings.NewReader(level), 3)
+		var parseErr *gs.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%s: expected a *ParseError, got %v", name, err)
+		}
+	}
+}
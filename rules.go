@@ -1,6 +1,105 @@
 package gridspech
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
+
+// ViolationKind describes which part of a tile's rule has been broken.
+type ViolationKind byte
+
+// Constants for ViolationKind
+const (
+	// ViolationGoalCount means that a goal's blob does not contain exactly two goals.
+	ViolationGoalCount ViolationKind = iota + 1
+
+	// ViolationGoalNeighbors means that a goal in a goal's blob does not have
+	// exactly one neighbor with the same color.
+	ViolationGoalNeighbors
+
+	// ViolationPathNeighbors means that a non-goal tile in a goal's blob does not have
+	// exactly two neighbors with the same color.
+	ViolationPathNeighbors
+
+	// ViolationCrownShared means that a crown's blob contains another crown.
+	ViolationCrownShared
+
+	// ViolationCrownUncovered means that some tiles with the same color as a crown are
+	// not in the blob of any crown with that color.
+	ViolationCrownUncovered
+
+	// ViolationDotCount means that a dot tile touches the wrong number of colored tiles.
+	ViolationDotCount
+
+	// ViolationJoinCount means that a join tile's blob contains the wrong number of
+	// non-blank tiles.
+	ViolationJoinCount
+
+	// ViolationRule is a violation reported by a TileRule registered outside of
+	// this package. Its Message describes the violation.
+	ViolationRule
+)
+
+// Violation is a reason that a tile is not valid.
+type Violation struct {
+	// Tile is the tile whose rule is broken.
+	Tile Tile
+	Kind ViolationKind
+
+	// Got and Want are the actual and expected counts for violations which
+	// involve counting something. They are zero otherwise.
+	Got, Want int
+
+	// Tiles are the tiles which cause the violation, sorted by X and then Y.
+	Tiles []TileCoord
+
+	// Message describes a ViolationRule.
+	Message string
+}
+
+func (v Violation) String() string {
+	switch v.Kind {
+	case ViolationGoalCount:
+		return fmt.Sprintf("goal blob has %d %s, wants %d", v.Got, plural(v.Got, "goal"), v.Want)
+	case ViolationGoalNeighbors:
+		return fmt.Sprintf("goal at %v has %d same-colored %s, wants %d", v.Tiles[0], v.Got, plural(v.Got, "neighbor"), v.Want)
+	case ViolationPathNeighbors:
+		return fmt.Sprintf("path tile at %v has %d same-colored %s, wants %d", v.Tiles[0], v.Got, plural(v.Got, "neighbor"), v.Want)
+	case ViolationCrownShared:
+		return fmt.Sprintf("crown blob contains second crown at %v", v.Tiles[0])
+	case ViolationCrownUncovered:
+		return fmt.Sprintf("%d %s with color %d not in a crown blob", len(v.Tiles), plural(len(v.Tiles), "tile"), v.Tile.Data.Color)
+	case ViolationDotCount:
+		return fmt.Sprintf("%v touches %d colored %s", v.Tile.Data.Type, v.Got, plural(v.Got, "tile"))
+	case ViolationJoinCount:
+		return fmt.Sprintf("%v blob has %d special %s, wants %d", v.Tile.Data.Type, v.Got, plural(v.Got, "tile"), v.Want)
+	case ViolationRule:
+		return v.Message
+	default:
+		return fmt.Sprintf("ViolationKind(%d)", v.Kind)
+	}
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+func sortedCoords(ts TileSet) []TileCoord {
+	coords := make([]TileCoord, 0, ts.Len())
+	for _, tile := range ts.Slice() {
+		coords = append(coords, tile.Coord)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].X != coords[j].X {
+			return coords[i].X < coords[j].X
+		}
+		return coords[i].Y < coords[j].Y
+	})
+	return coords
+}
 
 // Valid returns if all tiles in the grid are valid.
 func (g Grid) Valid() bool {
@@ -17,34 +116,31 @@ func (g Grid) Valid() bool {
 // ValidTile returns if t is valid in g. If all tiles in g are valid,
 // the grid is completed.
 func (g Grid) ValidTile(coord TileCoord) bool {
+	return len(g.TileViolations(coord)) == 0
+}
+
+// Violations returns the reasons that each tile in g is invalid, ordered by the
+// X and then Y coordinate of the invalid tile. A grid with no violations is completed.
+func (g Grid) Violations() []Violation {
+	var violations []Violation
+	for x := 0; x < g.Width(); x++ {
+		for y := 0; y < g.Height(); y++ {
+			violations = append(violations, g.TileViolations(TileCoord{X: x, Y: y})...)
+		}
+	}
+	return violations
+}
+
+// TileViolations returns the reasons that the tile at coord is invalid, or nil
+// if it is valid.
+func (g Grid) TileViolations(coord TileCoord) []Violation {
 	t := *g.TileAtCoord(coord)
 
-	switch t.Data.Type {
-	case TypeHole, TypeBlank:
-		return true
-	case TypeGoal:
-		return g.validGoal(t)
-	case TypeCrown:
-		return g.validCrown(t)
-	case TypeDot1:
-		return len(g.NeighborSliceWith(t.Coord, func(other Tile) bool {
-			return other.Data.Color != ColorNone
-		})) == 1
-	case TypeDot2:
-		return len(g.NeighborSliceWith(t.Coord, func(other Tile) bool {
-			return other.Data.Color != ColorNone
-		})) == 2
-	case TypeDot3:
-		return len(g.NeighborSliceWith(t.Coord, func(other Tile) bool {
-			return other.Data.Color != ColorNone
-		})) == 3
-	case TypeJoin1:
-		return g.validJoin(t, 1)
-	case TypeJoin2:
-		return g.validJoin(t, 2)
-	default:
+	rule := t.Data.Type.Rule()
+	if rule == nil {
 		panic(fmt.Sprintf("invalid tile type %v", t.Data.Type))
 	}
+	return rule.Violations(g, t)
 }
 
 // the blob of a goal tile should contain a direct path to another goal.
@@ -52,45 +148,80 @@ func (g Grid) ValidTile(coord TileCoord) bool {
 //   1. The blob should contain exactly two goals.
 //   2. The goals should have exactly 1 neighbor with the same state.
 //   3. All other tiles in the blob should have exactly 2 neighbors with the same state.
-func (g Grid) validGoal(start Tile) bool {
+func (g Grid) goalViolations(start Tile) []Violation {
+	var violations []Violation
+
 	blob := g.Blob(start.Coord)
-	var goals int
+	var goals TileSet
 	for _, t := range blob.Slice() {
+		neighborsSameColor := g.NeighborSliceWith(t.Coord, func(o Tile) bool {
+			return t.Data.Color == o.Data.Color
+		})
+
 		if t.Data.Type == TypeGoal {
-			goals++
+			goals.Add(t)
 
 			// requirement 2: The goals should have exactly 1 neighbor with the same state.
-			neighbors := g.NeighborSliceWith(t.Coord, func(o Tile) bool {
-				return t.Data.Color == o.Data.Color
-			})
-			if len(neighbors) != 1 {
-				return false
+			if len(neighborsSameColor) != 1 {
+				violations = append(violations, Violation{
+					Tile: start, Kind: ViolationGoalNeighbors,
+					Got: len(neighborsSameColor), Want: 1,
+					Tiles: []TileCoord{t.Coord},
+				})
 			}
+			continue
 		}
 
 		// requirement 3: All other tiles in the blob should have exactly 2 neighbors with the same state.
-		neighborsSameColor := g.NeighborSliceWith(t.Coord, func(o Tile) bool {
-			return t.Data.Color == o.Data.Color
-		})
-		if t.Data.Type != TypeGoal && len(neighborsSameColor) != 2 {
-			return false
+		if len(neighborsSameColor) != 2 {
+			violations = append(violations, Violation{
+				Tile: start, Kind: ViolationPathNeighbors,
+				Got: len(neighborsSameColor), Want: 2,
+				Tiles: []TileCoord{t.Coord},
+			})
 		}
 	}
 
 	// requirement 1: The blob should contain exactly two goals.
-	return goals == 2
+	if goals.Len() != 2 {
+		violations = append(violations, Violation{
+			Tile: start, Kind: ViolationGoalCount,
+			Got: goals.Len(), Want: 2,
+			Tiles: sortedCoords(goals),
+		})
+	}
+
+	// blob.Slice() is unordered, so make sure the output is consistent
+	sort.Slice(violations, func(i, j int) bool {
+		vi, vj := violations[i], violations[j]
+		if vi.Kind != vj.Kind {
+			return vi.Kind < vj.Kind
+		}
+		if vi.Tiles[0].X != vj.Tiles[0].X {
+			return vi.Tiles[0].X < vj.Tiles[0].X
+		}
+		return vi.Tiles[0].Y < vj.Tiles[0].Y
+	})
+
+	return violations
 }
 
 // crown tiles have the following requirements:
 //   1. No other crowns may be in this crown's blob.
 //   2. All tiles with the same color must have a crown in its blob.
-func (g Grid) validCrown(start Tile) bool {
+func (g Grid) crownViolations(start Tile) []Violation {
+	var violations []Violation
+
 	blob := g.Blob(start.Coord)
 
 	// requirement 1: No other crowns may be in this crown's blob.
-	for _, tile := range blob.Slice() {
+	for _, coord := range sortedCoords(blob) {
+		tile := *g.TileAtCoord(coord)
 		if tile.Data.Type == TypeCrown && tile != start {
-			return false
+			violations = append(violations, Violation{
+				Tile: start, Kind: ViolationCrownShared,
+				Tiles: []TileCoord{tile.Coord},
+			})
 		}
 	}
 
@@ -110,18 +241,44 @@ func (g Grid) validCrown(start Tile) bool {
 	})
 
 	// requirement 2: All tiles with the same color must have a crown in its blob.
-	return crownsBlobSet.Eq(stateSet)
+	stateSet.RemoveAll(crownsBlobSet)
+	if stateSet.Len() > 0 {
+		violations = append(violations, Violation{
+			Tile: start, Kind: ViolationCrownUncovered,
+			Tiles: sortedCoords(stateSet),
+		})
+	}
+
+	return violations
 }
 
-func (g Grid) validJoin(t Tile, n int) bool {
-	var found int
-	for _, blobTile := range g.Blob(t.Coord).Slice() {
-		if blobTile.Data.Type != TypeHole && blobTile.Data.Type != TypeBlank {
-			found++
-			if found > n+1 {
-				return false
-			}
-		}
+// dot tiles must touch exactly n tiles which are colored.
+func (g Grid) dotViolations(t Tile, n int) []Violation {
+	colored := g.NeighborSliceWith(t.Coord, func(other Tile) bool {
+		return other.Data.Color != ColorNone
+	})
+	if len(colored) == n {
+		return nil
+	}
+	return []Violation{{
+		Tile: t, Kind: ViolationDotCount,
+		Got: len(colored), Want: n,
+		Tiles: sortedCoords(NewTileSet(colored...)),
+	}}
+}
+
+// join tiles must have exactly n other non-blank tiles in their blob.
+func (g Grid) joinViolations(t Tile, n int) []Violation {
+	special := g.Blob(t.Coord)
+	special.RemoveIf(func(o Tile) bool {
+		return o.Data.Type == TypeHole || o.Data.Type == TypeBlank
+	})
+	if special.Len() == n+1 {
+		return nil
 	}
-	return found == n+1
+	return []Violation{{
+		Tile: t, Kind: ViolationJoinCount,
+		Got: special.Len(), Want: n + 1,
+		Tiles: sortedCoords(special),
+	}}
 }
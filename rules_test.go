@@ -0,0 +1,72 @@
+package gridspech_test
+
+import (
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+func TestViolations_invalidGrid(t *testing.T) {
+	grid := MakeInvalidGrid()
+	for _, tile := range grid.TilesWith(func(o gs.Tile) bool { return o.Data.Type != gs.TypeBlank }).Slice() {
+		if len(grid.TileViolations(tile.Coord)) == 0 {
+			t.Errorf("expected %v to have violations", tile)
+		}
+	}
+}
+
+func TestTileViolations(t *testing.T) {
+	const level = `
+	1e   1    1    1e   0
+	1    0    2k   0    2 
+	1e   1m2  0    1j1  1
+	`
+	grid := gs.MakeGridFromString(level, 3)
+
+	cases := []struct {
+		Coord    gs.TileCoord
+		Expected []string
+	}{
+		{gs.TileCoord{X: 0, Y: 0}, []string{
+			"goal blob has 3 goals, wants 2",
+			"goal at (0, 0) has 2 same-colored neighbors, wants 1",
+			"goal at (0, 2) has 2 same-colored neighbors, wants 1",
+			"path tile at (1, 0) has 1 same-colored neighbor, wants 2",
+		}},
+		{gs.TileCoord{X: 1, Y: 0}, []string{"Dot2 touches 1 colored tile"}},
+		{gs.TileCoord{X: 2, Y: 1}, []string{"1 tile with color 2 not in a crown blob"}},
+		{gs.TileCoord{X: 3, Y: 0}, []string{"Join1 blob has 1 special tile, wants 2"}},
+	}
+
+	for _, testCase := range cases {
+		violations := grid.TileViolations(testCase.Coord)
+		var actual []string
+		for _, v := range violations {
+			actual = append(actual, v.String())
+		}
+		if len(actual) != len(testCase.Expected) {
+			t.Errorf("%v:\nexpected: %q\ngot:      %q", testCase.Coord, testCase.Expected, actual)
+			continue
+		}
+		for i := range actual {
+			if actual[i] != testCase.Expected[i] {
+				t.Errorf("%v:\nexpected: %q\ngot:      %q", testCase.Coord, testCase.Expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestTileViolations_crownShared(t *testing.T) {
+	const level = `
+	0k  0k  1
+	`
+	grid := gs.MakeGridFromString(level, 2)
+	violations := grid.TileViolations(gs.TileCoord{X: 0, Y: 0})
+	if len(violations) != 1 || violations[0].Kind != gs.ViolationCrownShared {
+		t.Fatalf("expected a single ViolationCrownShared, got %v", violations)
+	}
+	if expected := "crown blob contains second crown at (1, 0)"; violations[0].String() != expected {
+		t.Errorf("expected %q, got %q", expected, violations[0].String())
+	}
+}
@@ -1,85 +1,180 @@
 package gridspech
 
 import (
+	"bufio"
+	"fmt"
+	"io"
 	"strings"
+	"unicode"
 )
 
-func stringToTileData(s string) TileData {
+// ParseOptions configures how ParseGrid reads a level.
+type ParseOptions struct {
+	// MaxColors is the number of colors available in the level. Every tile in
+	// the level must have a color less than MaxColors.
+	MaxColors int
+}
+
+// ParseError describes a problem found while parsing a level. Line and Column
+// are 1-based, and point at the start of the offending token.
+type ParseError struct {
+	Line, Column int
+	Token        string
+	Msg          string
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %q", e.Line, e.Column, e.Msg, e.Token)
+}
+
+// parseTileData parses a single tile token, such as `1/m2<^`. The format is
+// `${color}${sticky}${type}${arrows}`, or `_` for a hole. If the token is
+// malformed, a description of the problem is returned.
+func parseTileData(tok string) (TileData, string) {
 	var data TileData
-	if strings.Contains(s, "_") {
-		data.Type = TypeHole
-	} else {
-		data.Color = TileColor(s[0] - '0')
-		data.Sticky = strings.Contains(s, "/")
-		data.Type = TypeBlank
-		switch {
-		case strings.Contains(s, "k"):
-			data.Type = TypeCrown
-		case strings.Contains(s, "e"):
-			data.Type = TypeGoal
-		case strings.Contains(s, "m1"):
-			data.Type = TypeDot1
-		case strings.Contains(s, "m2"):
-			data.Type = TypeDot2
-		case strings.Contains(s, "m3"):
-			data.Type = TypeDot3
-		case strings.Contains(s, "j1"):
-			data.Type = TypeJoin1
-		case strings.Contains(s, "j2"):
-			data.Type = TypeJoin2
-		}
-		if strings.Contains(s, "^") {
-			data.ArrowNorth = true
-		}
-		if strings.Contains(s, ">") {
-			data.ArrowEast = true
-		}
-		if strings.Contains(s, "v") {
-			data.ArrowSouth = true
+	if tok == "_" {
+		return data, ""
+	}
+
+	if tok[0] < '0' || tok[0] > '9' {
+		return data, "tile must start with a color or be a hole"
+	}
+	data.Color = TileColor(tok[0] - '0')
+	rest := tok[1:]
+
+	if strings.HasPrefix(rest, "/") {
+		data.Sticky = true
+		rest = rest[1:]
+	}
+
+	var tokenLen int
+	data.Type, tokenLen = typeForToken(rest)
+	rest = rest[tokenLen:]
+
+	for _, r := range rest {
+		var arrow *bool
+		switch r {
+		case '^':
+			arrow = &data.ArrowNorth
+		case '>':
+			arrow = &data.ArrowEast
+		case 'v':
+			arrow = &data.ArrowSouth
+		case '<':
+			arrow = &data.ArrowWest
+		default:
+			return data, "unknown tile token"
 		}
-		if strings.Contains(s, "<") {
-			data.ArrowWest = true
+		if *arrow {
+			return data, "duplicate arrow in tile"
 		}
+		*arrow = true
 	}
-	return data
+
+	return data, ""
 }
 
-func emptyLine(s string) bool {
-	return !strings.ContainsAny(s, "_0123456789")
+type rowToken struct {
+	text   string
+	column int
 }
 
-func stripEmptyLines(lines []string) []string {
-	if emptyLine(lines[0]) {
-		lines = lines[1:]
+// splitRow splits a line into its whitespace-separated tokens, keeping track of
+// the (1-based) column that each token starts at.
+func splitRow(line string) []rowToken {
+	var tokens []rowToken
+	var cur strings.Builder
+	var start int
+
+	col := 0
+	for _, r := range line {
+		col++
+		if unicode.IsSpace(r) {
+			if cur.Len() > 0 {
+				tokens = append(tokens, rowToken{text: cur.String(), column: start})
+				cur.Reset()
+			}
+			continue
+		}
+		if cur.Len() == 0 {
+			start = col
+		}
+		cur.WriteRune(r)
 	}
-	if emptyLine(lines[len(lines)-1]) {
-		lines = lines[:len(lines)-1]
+	if cur.Len() > 0 {
+		tokens = append(tokens, rowToken{text: cur.String(), column: start})
 	}
-	return lines
+	return tokens
 }
 
-// MakeGridFromString takes a string and converts it into a Grid.
-func MakeGridFromString(str string, maxColors int) Grid {
-	var grid Grid
+// ParseGrid reads a level from r. Each line of the level is a row of
+// whitespace-separated tiles, with the top row first. Blank lines before and
+// after the level are ignored.
+//
+// If the level is malformed, the returned error will be a *ParseError.
+func ParseGrid(r io.Reader, opts ParseOptions) (Grid, error) {
+	if opts.MaxColors < 1 {
+		return Grid{}, fmt.Errorf("gridspech: MaxColors must be at least 1, got %d", opts.MaxColors)
+	}
+
+	type row struct {
+		line   int
+		tokens []rowToken
+	}
 
-	lines := strings.Split(str, "\n")
-	lines = stripEmptyLines(lines)
+	var rows []row
+	var blankLine int
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		tokens := splitRow(scanner.Text())
+		if len(tokens) == 0 {
+			if len(rows) > 0 && blankLine == 0 {
+				blankLine = lineNum
+			}
+			continue
+		}
+		if blankLine != 0 {
+			return Grid{}, &ParseError{Line: blankLine, Msg: "blank line inside of level"}
+		}
+		rows = append(rows, row{line: lineNum, tokens: tokens})
+	}
+	if err := scanner.Err(); err != nil {
+		return Grid{}, err
+	}
+	if len(rows) == 0 {
+		return Grid{}, &ParseError{Line: 1, Msg: "level is empty"}
+	}
 
-	height := len(lines)
-	width := len(strings.Fields(lines[0]))
+	height := len(rows)
+	width := len(rows[0].tokens)
 
+	var grid Grid
+	grid.MaxColors = opts.MaxColors
 	grid.Tiles = make([][]Tile, width)
 	for x := range grid.Tiles {
 		grid.Tiles[x] = make([]Tile, height)
 	}
-	grid.MaxColors = maxColors
 
-	for y := 0; y < height; y++ {
-		row := strings.Fields(lines[height-y-1])
+	for i, row := range rows {
+		if len(row.tokens) != width {
+			return Grid{}, &ParseError{
+				Line: row.line,
+				Msg:  fmt.Sprintf("row has %d tiles, but the first row has %d", len(row.tokens), width),
+			}
+		}
 
-		for x := 0; x < width; x++ {
-			cur := row[x]
-			data := stringToTileData(cur)
+		y := height - i - 1
+		for x, tok := range row.tokens {
+			data, problem := parseTileData(tok.text)
+			if problem == "" && data.Type != TypeHole && int(data.Color) >= opts.MaxColors {
+				problem = fmt.Sprintf("color must be less than %d", opts.MaxColors)
+			}
+			if problem != "" {
+				return Grid{}, &ParseError{Line: row.line, Column: tok.column, Token: tok.text, Msg: problem}
+			}
 			grid.Tiles[x][y] = Tile{
 				Data:  data,
 				Coord: TileCoord{X: x, Y: y},
@@ -87,6 +182,16 @@ func MakeGridFromString(str string, maxColors int) Grid {
 		}
 	}
 
+	return grid, nil
+}
+
+// MakeGridFromString takes a string and converts it into a Grid. It panics if
+// the string is not a valid level; use ParseGrid to handle malformed levels.
+func MakeGridFromString(str string, maxColors int) Grid {
+	grid, err := ParseGrid(strings.NewReader(str), ParseOptions{MaxColors: maxColors})
+	if err != nil {
+		panic(err)
+	}
 	return grid
 }
 
@@ -0,0 +1,77 @@
+package gridspech_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+func TestParseGrid(t *testing.T) {
+	const level = `
+	1/m2<^  _
+	0e      2j2v>
+	`
+	grid, err := gs.ParseGrid(strings.NewReader(level), gs.ParseOptions{MaxColors: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		Actual, Expected gs.TileData
+	}{
+		{grid.TileAt(0, 1).Data, gs.TileData{Type: gs.TypeDot2, Color: 1, Sticky: true, ArrowWest: true, ArrowNorth: true}},
+		{grid.TileAt(1, 1).Data, gs.TileData{Type: gs.TypeHole}},
+		{grid.TileAt(0, 0).Data, gs.TileData{Type: gs.TypeGoal}},
+		{grid.TileAt(1, 0).Data, gs.TileData{Type: gs.TypeJoin2, Color: 2, ArrowSouth: true, ArrowEast: true}},
+	}
+
+	for _, testCase := range cases {
+		if testCase.Expected != testCase.Actual {
+			t.Errorf("\nexpected: %#v\ngot:      %#v\n", testCase.Expected, testCase.Actual)
+		}
+	}
+}
+
+func TestParseGrid_errors(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Level    string
+		Expected gs.ParseError
+	}{
+		{"empty", "\n  \n", gs.ParseError{Line: 1}},
+		{"ragged", "0 0\n0\n", gs.ParseError{Line: 2}},
+		{"blank line", "0 0\n\n0 0\n", gs.ParseError{Line: 2}},
+		{"unknown token", "0  1x\n", gs.ParseError{Line: 1, Column: 4, Token: "1x"}},
+		{"bad color", "0\n0\n3k\n", gs.ParseError{Line: 3, Column: 1, Token: "3k"}},
+		{"no color", "0 e\n", gs.ParseError{Line: 1, Column: 3, Token: "e"}},
+		{"duplicate arrow", "\t0^^\n", gs.ParseError{Line: 1, Column: 2, Token: "0^^"}},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := gs.ParseGrid(strings.NewReader(testCase.Level), gs.ParseOptions{MaxColors: 3})
+			var parseErr *gs.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a *ParseError, got %v", err)
+			}
+			actual := *parseErr
+			actual.Msg = ""
+			if actual != testCase.Expected {
+				t.Errorf("\nexpected: %#v\ngot:      %#v (%v)", testCase.Expected, actual, err)
+			}
+		})
+	}
+}
+
+func TestParseGrid_roundTrip(t *testing.T) {
+	grid := MakeValidGrid()
+	parsed, err := gs.ParseGrid(strings.NewReader(grid.String()), gs.ParseOptions{MaxColors: grid.MaxColors})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.String() != grid.String() {
+		t.Errorf("\nexpected:\n%v\ngot:\n%v", grid, parsed)
+	}
+}
@@ -0,0 +1,89 @@
+package solve
+
+import (
+	"context"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// Backbone returns the colors that each tile has across all solutions of g, indexed in the same
+// way as g.Grid.Tiles. Tiles whose set contains a single color have that color in every solution.
+// Every set is empty if g has no solutions, and holes always have an empty set.
+//
+// Unknown tiles which cannot affect whether g is solved may have any color that is not forbidden.
+func (g GridSolver) Backbone() ([][]ColorSet, error) {
+	return g.BackboneContext(context.Background())
+}
+
+// BackboneContext is like Backbone, but stops once ctx is cancelled, in which case ctx.Err()
+// is returned.
+//
+// Rather than finding every solution, it asks whether each tile can have each color, one
+// tile and color at a time. A question is answered by pinning the tile to the color on a copy
+// of g, and finding a single solution. Every solution which is found shows the colors of the
+// rest of the tiles as well, so most questions are answered without solving anything.
+func (g GridSolver) BackboneContext(ctx context.Context) ([][]ColorSet, error) {
+	backbone := make([][]ColorSet, g.Grid.Width())
+	for x, col := range g.Grid.Tiles {
+		backbone[x] = make([]ColorSet, len(col))
+	}
+	add := func(colors gs.Assignment) {
+		for _, coord := range colors.Coords() {
+			if g.Grid.TileAtCoord(coord).Data.Type != gs.TypeHole {
+				color, _ := colors.Get(coord)
+				backbone[coord.X][coord.Y] |= SingleColor(color)
+			}
+		}
+	}
+
+	colors, ok, err := g.findColoring(ctx, gs.Assignment{})
+	if err != nil || !ok {
+		return backbone, err
+	}
+	add(colors)
+
+	for x := 0; x < g.Grid.Width(); x++ {
+		for y := 0; y < g.Grid.Height(); y++ {
+			coord := gs.TileCoord{X: x, Y: y}
+			if g.Grid.TileAtCoord(coord).Data.Type == gs.TypeHole || !g.UnknownTiles.Has(coord) {
+				continue
+			}
+			for _, color := range (g.allowedColors(coord) &^ backbone[x][y]).Colors() {
+				var pin gs.Assignment
+				pin.Set(coord, color)
+				colors, ok, err := g.findColoring(ctx, pin)
+				if err != nil {
+					return backbone, err
+				}
+				if ok {
+					add(colors)
+				}
+			}
+		}
+	}
+	return backbone, nil
+}
+
+// findColoring returns the colors of every tile in a single solution of g where the tiles in
+// pinned have their colors in pinned, or false if there is no such solution.
+func (g GridSolver) findColoring(ctx context.Context, pinned gs.Assignment) (gs.Assignment, bool, error) {
+	g = g.Clone()
+	g.Sorted = false
+	for _, coord := range pinned.Coords() {
+		color, _ := pinned.Get(coord)
+		if err := g.Pin(coord, color); err != nil {
+			return gs.Assignment{}, false, nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	solutions := g.IterateAllTiles(ctx)
+	defer solutions.Close()
+
+	solution, ok := solutions.Next()
+	if !ok {
+		return gs.Assignment{}, false, ctx.Err()
+	}
+	return g.coloring(solution), true, nil
+}
@@ -0,0 +1,126 @@
+package solve_test
+
+import (
+	"context"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestGridSolver_Backbone(t *testing.T) {
+	paired := func(g *solve.GridSolver) {
+		if err := g.PairGoals(gs.TileCoord{X: 0, Y: 0}, gs.TileCoord{X: 0, Y: 2}); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Forbid(gs.TileCoord{X: 1, Y: 2}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	followsPaired := func(grid gs.Grid) bool {
+		return grid.Blob(gs.TileCoord{X: 0, Y: 0}).Has(*grid.TileAt(0, 2)) && grid.TileAt(1, 2).Data.Color != 0
+	}
+
+	cases := []struct {
+		level   string
+		setup   func(g *solve.GridSolver)
+		follows func(grid gs.Grid) bool
+	}{
+		{level: "0e  0  0e\n0  0  0\n0e  0  0e"},
+		{level: "0m2  0  0\n0  0  0m1"},
+		{level: "0j1  0  0\n0  0  0j1"},
+		{level: "0e  0  0\n0e  0  0e"},
+		{level: "0e  0  0e\n0  0  0\n0e  0  0e", setup: paired, follows: followsPaired},
+	}
+	engines := []struct {
+		name   string
+		engine solve.Engine
+	}{
+		{name: "pipeline"},
+		{name: "propagate", engine: solve.PropagationEngine{}},
+		{name: "sat", engine: solve.SATEngine{OnError: func(err error) { t.Error(err) }}},
+	}
+
+	var forced, free int
+	for _, c := range cases {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(c.level, 2))
+		if c.setup != nil {
+			c.setup(&solver)
+		}
+
+		// the colors of each tile across every valid coloring of the grid
+		width, height := solver.Grid.Width(), solver.Grid.Height()
+		expected := make([][]solve.ColorSet, width)
+		for x := range expected {
+			expected[x] = make([]solve.ColorSet, height)
+		}
+		for bits := 0; bits < 1<<(width*height); bits++ {
+			grid := solver.Grid.Clone()
+			for i := 0; i < width*height; i++ {
+				grid.TileAt(i%width, i/width).Data.Color = gs.TileColor(bits >> i & 1)
+			}
+			if !grid.Valid() || c.follows != nil && !c.follows(grid) {
+				continue
+			}
+			for x, col := range grid.Tiles {
+				for y, tile := range col {
+					expected[x][y] |= solve.SingleColor(tile.Data.Color)
+				}
+			}
+		}
+
+		for x := range expected {
+			for y := range expected[x] {
+				switch expected[x][y].Len() {
+				case 1:
+					forced++
+				case 2:
+					free++
+				}
+			}
+		}
+
+		for _, e := range engines {
+			solver.Engine = e.engine
+			backbone, err := solver.Backbone()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for x := range expected {
+				for y := range expected[x] {
+					if backbone[x][y] != expected[x][y] {
+						t.Errorf("%s: expected %v at (%d, %d), got %v for level\n%s", e.name, expected[x][y], x, y, backbone[x][y], c.level)
+					}
+				}
+			}
+		}
+	}
+	if forced == 0 || free == 0 {
+		t.Errorf("expected levels to have both forced and free tiles, got %d forced and %d free", forced, free)
+	}
+}
+
+func TestGridSolver_Backbone_unsolvable(t *testing.T) {
+	solver := solve.NewGridSolver(gs.MakeGridFromString("0e  0  0", 2))
+	backbone, err := solver.Backbone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := range backbone {
+		for y := range backbone[x] {
+			if backbone[x][y] != 0 {
+				t.Errorf("expected no colors at (%d, %d), got %v", x, y, backbone[x][y])
+			}
+		}
+	}
+}
+
+func TestGridSolver_BackboneContext_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	solver := solve.NewGridSolver(gs.MakeGridFromString("0e  0  0e\n0  0  0\n0e  0  0e", 2))
+	if _, err := solver.BackboneContext(ctx); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/deanveloper/gridspech-go"
 	"github.com/deanveloper/gridspech-go/solve"
@@ -14,6 +20,14 @@ import (
 const (
 	outputString = "lines"
 	outputJSON   = "json"
+
+	inputText   = "text"
+	inputNative = "native"
+
+	enginePipeline  = "pipeline"
+	enginePropagate = "propagate"
+	engineExternal  = "external"
+	engineSAT       = "sat"
 )
 
 var (
@@ -25,39 +39,84 @@ var (
 	solveDots   = getopt.BoolLong("dots", 'd', "solve all dot tiles")
 	solveJoins  = getopt.BoolLong("joins", 'j', "solve all join tiles")
 	solveAll    = getopt.BoolLong("all", 'a', "solve all tiles")
-	jsonOutput  = getopt.EnumLong("format", 'f', []string{outputString, outputJSON}, "", "output format (lines or json)")
+	jsonOutput  = getopt.EnumLong("format", 'f', []string{outputString, outputJSON}, outputString, "output format (lines or json)")
+	inputFormat = getopt.EnumLong("input", 'i', []string{inputText, inputNative}, inputText, "input format (text or native)")
+	intended    = getopt.StringLong("intended", 0, "", "find solutions which differ from the intended solution in `file`")
+	hints       = getopt.BoolLong("hints", 0, "explain the deductions which can be made about the level")
+	backbone    = getopt.BoolLong("backbone", 0, "print the colors that each tile can have across all solutions")
+	engine      = getopt.EnumLong("engine", 'e', []string{enginePipeline, enginePropagate, engineSAT, engineExternal}, enginePipeline, "engine used to solve all tiles (pipeline, propagate, sat, or external)")
+	satCommand  = getopt.StringLong("sat-command", 0, "", "SAT solver `command` used by the external engine, ie \"kissat -q\"")
+	dimacs      = getopt.BoolLong("dimacs", 0, "print the level as a CNF formula in the DIMACS format")
+	sorted      = getopt.BoolLong("sorted", 's', "print solutions in the same order every run (waits for all solutions to be found)")
+	limit       = getopt.IntLong("limit", 'l', 0, "stop after printing `n` solutions")
+	maxNodes    = getopt.Int64Long("max-nodes", 0, 0, "stop after exploring `n` search nodes")
+	timeout     = getopt.DurationLong("timeout", 0, 0, "stop searching after `duration`, ie 30s")
+	showStats   = getopt.BoolLong("stats", 0, "print search statistics to standard error every second, and once solving is done")
+	parallel    = getopt.IntLong("parallel", 'P', 0, "solve using `n` goroutines at once (defaults to the number of CPUs)")
+
+	pins   repeatedFlag
+	forbid repeatedFlag
+	pairs  repeatedFlag
 )
 
-func solutionsFromFlags(solver solve.GridSolver) <-chan gridspech.TileSet {
-	var ch <-chan gridspech.TileSet
+func init() {
+	getopt.FlagLong(&pins, "pin", 0, "require the tile at x,y to have color c. may be repeated", "x,y=c")
+	getopt.FlagLong(&forbid, "forbid", 0, "forbid the tile at x,y from having color c. may be repeated", "x,y=c")
+	getopt.FlagLong(&pairs, "pair", 0, "require the goals at x1,y1 and x2,y2 to be connected. may be repeated", "x1,y1:x2,y2")
+}
+
+// repeatedFlag is a getopt.Value which keeps each value that its option is given.
+type repeatedFlag []string
+
+func (f *repeatedFlag) Set(value string, _ getopt.Option) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func (f *repeatedFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+// jsonSolution is a single line of output when using `--format json`.
+type jsonSolution struct {
+	Grid    gridspech.Grid        `json:"grid"`
+	Changed gridspech.TileSet     `json:"changed"`
+	Differs []gridspech.TileCoord `json:"differs,omitempty"`
+}
+
+func solutionsFromFlags(ctx context.Context, solver solve.GridSolver) solve.SolutionIterator {
 	if *solveAll {
-		ch = solver.SolveAllTiles()
-	} else {
-		{
-			tempCh := make(chan gridspech.TileSet, 1)
-			tempCh <- gridspech.NewTileSet()
-			close(tempCh)
-			ch = tempCh
-		}
+		return solver.IterateAllTiles(ctx)
+	}
 
-		if getopt.IsSet('t') {
-			tiles := parseCoords(*solveTiles)
-			ch = solve.MergeSolutionsIters(ch, solver.SolveTiles(tiles...))
-		}
-		if *solveGoals {
-			ch = solve.MergeSolutionsIters(ch, solver.SolveGoals())
-		}
-		if *solveJoins {
-			ch = solve.MergeSolutionsIters(ch, solver.SolveGoals())
-		}
-		if *solveDots {
-			ch = solve.MergeSolutionsIters(ch, solver.SolveGoals())
-		}
-		if *solveCrowns {
-			ch = solve.MergeSolutionsIters(ch, solver.SolveGoals())
-		}
+	var ch <-chan gridspech.Assignment
+	{
+		tempCh := make(chan gridspech.Assignment, 1)
+		tempCh <- gridspech.Assignment{}
+		close(tempCh)
+		ch = tempCh
+	}
+
+	if getopt.IsSet('t') {
+		tiles := parseCoords(*solveTiles)
+		ch = solve.MergeSolutionsItersContext(ctx, ch, solver.SolveTilesContext(ctx, tiles...))
+	}
+	if *solveGoals {
+		ch = solve.MergeSolutionsItersContext(ctx, ch, solver.SolveGoalsContext(ctx))
+	}
+	if *solveJoins {
+		ch = solve.MergeSolutionsItersContext(ctx, ch, solver.SolveGoalsContext(ctx))
+	}
+	if *solveDots {
+		ch = solve.MergeSolutionsItersContext(ctx, ch, solver.SolveGoalsContext(ctx))
+	}
+	if *solveCrowns {
+		ch = solve.MergeSolutionsItersContext(ctx, ch, solver.SolveGoalsContext(ctx))
 	}
-	return ch
+	if *sorted {
+		return solve.SortSolutions(solve.ChanToIter(ch))
+	}
+	return solve.ChanToIter(ch)
 }
 
 func parseCoords(coordsStr []string) []gridspech.TileCoord {
@@ -74,6 +133,38 @@ func parseCoords(coordsStr []string) []gridspech.TileCoord {
 	return coords
 }
 
+// applyInputs pins, forbids, and pairs the tiles given by --pin, --forbid, and --pair.
+func applyInputs(solver *solve.GridSolver) error {
+	for _, pin := range pins {
+		var x, y, c int
+		if n, err := fmt.Sscanf(pin, "%d,%d=%d", &x, &y, &c); err != nil || n != 3 {
+			return fmt.Errorf("invalid --pin %q, expected x,y=c", pin)
+		}
+		if err := solver.Pin(gridspech.TileCoord{X: x, Y: y}, gridspech.TileColor(c)); err != nil {
+			return err
+		}
+	}
+	for _, f := range forbid {
+		var x, y, c int
+		if n, err := fmt.Sscanf(f, "%d,%d=%d", &x, &y, &c); err != nil || n != 3 {
+			return fmt.Errorf("invalid --forbid %q, expected x,y=c", f)
+		}
+		if err := solver.Forbid(gridspech.TileCoord{X: x, Y: y}, gridspech.TileColor(c)); err != nil {
+			return err
+		}
+	}
+	for _, pair := range pairs {
+		var x1, y1, x2, y2 int
+		if n, err := fmt.Sscanf(pair, "%d,%d:%d,%d", &x1, &y1, &x2, &y2); err != nil || n != 4 {
+			return fmt.Errorf("invalid --pair %q, expected x1,y1:x2,y2", pair)
+		}
+		if err := solver.PairGoals(gridspech.TileCoord{X: x1, Y: y1}, gridspech.TileCoord{X: x2, Y: y2}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func main() {
 	getopt.HelpColumn = 22
 	getopt.SetUsage(func() {
@@ -86,7 +177,7 @@ func main() {
 		getopt.CommandLine.PrintOptions(os.Stderr)
 	})
 	getopt.Parse()
-	if !getopt.IsSet('a') && !getopt.IsSet('t') && !getopt.IsSet('g') && !getopt.IsSet('c') && !getopt.IsSet('d') && !getopt.IsSet('j') {
+	if !getopt.IsSet('a') && !getopt.IsSet('t') && !getopt.IsSet('g') && !getopt.IsSet('c') && !getopt.IsSet('d') && !getopt.IsSet('j') && *intended == "" && !*hints && !*backbone && !*dimacs {
 		getopt.Usage()
 		return
 	}
@@ -114,19 +205,276 @@ func main() {
 		log.Fatalln("standard input is over 10000 bytes... are you sure it is a gridspech level?")
 	}
 
-	level := string(levelBytes)
-	solver := solve.NewGridSolver(gridspech.MakeGridFromString(level, *maxColors))
+	grid, err := parseLevel(strings.NewReader(string(levelBytes)))
+	if err != nil {
+		log.Fatalln("error parsing level:", err)
+	}
+	solver := solve.NewGridSolver(grid)
+	if err := applyInputs(&solver); err != nil {
+		log.Fatalln("error:", err)
+	}
+	solver.Sorted = *sorted
+	solver.Workers = *parallel
+	switch *engine {
+	case enginePropagate:
+		solver.Engine = solve.PropagationEngine{OnError: func(err error) { log.Fatalln("error:", err) }}
+	case engineSAT:
+		solver.Engine = solve.SATEngine{OnError: func(err error) { log.Fatalln("error:", err) }}
+	case engineExternal:
+		command := strings.Fields(*satCommand)
+		if len(command) == 0 {
+			log.Fatalln("the external engine requires --sat-command")
+		}
+		solver.Engine = solve.ExternalEngine{
+			Command: command[0],
+			Args:    command[1:],
+			OnError: func(err error) { log.Fatalln("error:", err) },
+		}
+	}
 
-	solutions := solutionsFromFlags(solver)
+	if *intended != "" {
+		printUnintendedSolutions(solver)
+		return
+	}
+	if *hints {
+		printDeductions(solver)
+		return
+	}
+	if *backbone {
+		printBackbone(solver)
+		return
+	}
+	if *dimacs {
+		enc, err := solver.EncodeCNF()
+		if err != nil {
+			log.Fatalln("error:", err)
+		}
+		if err := enc.WriteDIMACS(os.Stdout); err != nil {
+			log.Fatalln("error:", err)
+		}
+		return
+	}
+
+	var stats solve.Stats
+	if *showStats {
+		solver.Observer = &stats
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go solve.PrintProgress(ctx, os.Stderr, time.Second, &stats)
+	}
+
+	opts := solve.SolveOptions{Limit: *limit, MaxNodes: *maxNodes, Timeout: *timeout}
+	search := solve.NewSearch(context.Background(), opts, func(ctx context.Context) solve.SolutionIterator {
+		return solutionsFromFlags(ctx, solver)
+	})
+	defer search.Close()
 
+	encoder := json.NewEncoder(os.Stdout)
 	first := true
-	for solution := range solutions {
+	for {
+		solution, ok := search.Next()
+		if !ok {
+			break
+		}
+		newGrid := solver.Grid.Clone()
+		solution.Apply(newGrid)
+
+		if *jsonOutput == outputJSON {
+			if err := encoder.Encode(jsonSolution{Grid: newGrid, Changed: changedTiles(solver.Grid, newGrid)}); err != nil {
+				log.Fatalln("error:", err)
+			}
+			continue
+		}
+
 		if !first {
 			fmt.Println()
 		}
-
-		newGrid := solver.Grid.Clone()
-		newGrid.ApplyTileSet(solution)
+		first = false
 		fmt.Println(newGrid)
 	}
+
+	if *showStats {
+		fmt.Fprintln(os.Stderr, &stats)
+	}
+	if *solveAll && search.Status() == solve.StatusExhausted && search.Found() == 0 {
+		printDiagnosis(solver)
+	}
+	if *showStats || getopt.IsSet("limit") || getopt.IsSet("max-nodes") || getopt.IsSet("timeout") {
+		fmt.Fprintf(os.Stderr, "search %v: %d solutions, %d nodes\n", search.Status(), search.Found(), search.Nodes())
+	}
+}
+
+func parseLevel(r io.Reader) (gridspech.Grid, error) {
+	if *inputFormat == inputNative {
+		return gridspech.ReadNative(r, *maxColors)
+	}
+	return gridspech.ParseGrid(r, gridspech.ParseOptions{MaxColors: *maxColors})
+}
+
+// changedTiles returns the tiles in solved which are different from the tiles in original.
+func changedTiles(original, solved gridspech.Grid) gridspech.TileSet {
+	return solved.TilesWith(func(o gridspech.Tile) bool {
+		return o != *original.TileAtCoord(o.Coord)
+	})
+}
+
+func printUnintendedSolutions(solver solve.GridSolver) {
+	file, err := os.Open(*intended)
+	if err != nil {
+		log.Fatalln("error:", err)
+	}
+	intendedGrid, err := parseLevel(file)
+	file.Close()
+	if err != nil {
+		log.Fatalln("error parsing intended solution:", err)
+	}
+
+	unintended, err := solver.UnintendedSolutions(context.Background(), intendedGrid)
+	if err != nil {
+		log.Fatalln("error:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	var count int
+	for solution := range unintended {
+		count++
+		if *jsonOutput == outputJSON {
+			differs := solution.Diff.Slice()
+			sort.Slice(differs, func(i, j int) bool {
+				if differs[i].Y != differs[j].Y {
+					return differs[i].Y > differs[j].Y
+				}
+				return differs[i].X < differs[j].X
+			})
+			err := encoder.Encode(jsonSolution{
+				Grid:    solution.Grid,
+				Changed: changedTiles(solver.Grid, solution.Grid),
+				Differs: differs,
+			})
+			if err != nil {
+				log.Fatalln("error:", err)
+			}
+			continue
+		}
+
+		if count > 1 {
+			fmt.Println()
+		}
+		fmt.Printf("unintended solution %d (%d tiles differ, marked with *):\n", count, solution.Diff.Len())
+		fmt.Println(highlightedGrid(solution.Grid, solution.Diff))
+	}
+
+	if *jsonOutput != outputJSON && count == 0 {
+		fmt.Println("no unintended solutions")
+	}
+}
+
+func printDeductions(solver solve.GridSolver) {
+	deductions, err := solver.Deductions()
+	for _, deduction := range deductions {
+		fmt.Println(deduction)
+	}
+	if err != nil {
+		log.Fatalln("error:", err)
+	}
+	if len(deductions) == 0 {
+		fmt.Println("no deductions can be made")
+	}
+}
+
+// printDiagnosis explains why solver has no solutions by printing a minimal set of tiles which
+// cannot all be satisfied to standard error. The tiles are marked with a * in the level.
+func printDiagnosis(solver solve.GridSolver) {
+	var unsat *solve.UnsatisfiableError
+	switch err := solver.Diagnose(); {
+	case err == nil:
+		return
+	case errors.As(err, &unsat):
+		fmt.Fprintf(os.Stderr, "no solutions: %v\n", unsat)
+		fmt.Fprintln(os.Stderr, highlightedGrid(solver.Grid, gridspech.NewTileCoordSet(unsat.Core...)))
+	default:
+		log.Fatalln("error:", err)
+	}
+}
+
+// printBackbone prints the colors that each tile can have as a grid. Tiles which have the same
+// color in every solution are marked with a *, and holes are printed as _.
+func printBackbone(solver solve.GridSolver) {
+	colors, err := solver.Backbone()
+	if err != nil {
+		log.Fatalln("error:", err)
+	}
+
+	var solvable bool
+	cells := make([][]string, len(colors))
+	forced := gridspech.NewTileCoordSet()
+	for x, col := range colors {
+		cells[x] = make([]string, len(col))
+		for y, set := range col {
+			if solver.Grid.TileAt(x, y).Data.Type == gridspech.TypeHole {
+				cells[x][y] = "_"
+				continue
+			}
+			solvable = solvable || set != 0
+			for _, c := range set.Colors() {
+				cells[x][y] += fmt.Sprint(c)
+			}
+			if set.Len() == 1 {
+				forced.Add(gridspech.TileCoord{X: x, Y: y})
+				cells[x][y] += "*"
+			}
+		}
+	}
+	if !solvable {
+		fmt.Println("no solutions")
+		return
+	}
+
+	var longest int
+	for _, col := range cells {
+		for _, cell := range col {
+			if len(cell) > longest {
+				longest = len(cell)
+			}
+		}
+	}
+	for y := solver.Grid.Height() - 1; y >= 0; y-- {
+		var row []string
+		for x := range cells {
+			row = append(row, cells[x][y]+strings.Repeat(" ", longest-len(cells[x][y])))
+		}
+		fmt.Println(strings.TrimRight(strings.Join(row, "  "), " "))
+	}
+	fmt.Printf("%d tiles have the same color in every solution\n", forced.Len())
+}
+
+// highlightedGrid formats g like gridspech.Grid.String, but with a * after each tile in highlight.
+func highlightedGrid(g gridspech.Grid, highlight gridspech.TileCoordSet) string {
+	var longest int
+	for _, col := range g.Tiles {
+		for _, tile := range col {
+			if str := tile.Data.String(); len(str)+1 > longest {
+				longest = len(str) + 1
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for y := g.Height() - 1; y >= 0; y-- {
+		if y < g.Height()-1 {
+			sb.WriteByte('\n')
+		}
+		for x := 0; x < g.Width(); x++ {
+			tile := g.TileAt(x, y)
+			tileStr := tile.Data.String()
+			if highlight.Has(tile.Coord) {
+				tileStr += "*"
+			}
+			if x > 0 {
+				sb.WriteString("  ")
+			}
+			sb.WriteString(tileStr + strings.Repeat(" ", longest-len(tileStr)))
+		}
+	}
+	return sb.String()
 }
@@ -0,0 +1,85 @@
+package solve
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CNF is a boolean formula in conjunctive normal form. Variables are numbered starting
+// at 1, and each literal is either a variable or its negation, like in the DIMACS format.
+type CNF struct {
+	NumVars int
+	Clauses [][]int
+}
+
+// NewVar adds a variable to f and returns it.
+func (f *CNF) NewVar() int {
+	f.NumVars++
+	return f.NumVars
+}
+
+// AddClause adds a clause to f which is true if any of lits are true.
+func (f *CNF) AddClause(lits ...int) {
+	f.Clauses = append(f.Clauses, append([]int(nil), lits...))
+}
+
+// WriteDIMACS writes f to w in the DIMACS CNF format.
+func (f CNF) WriteDIMACS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p cnf %d %d\n", f.NumVars, len(f.Clauses))
+	for _, clause := range f.Clauses {
+		for _, lit := range clause {
+			bw.WriteString(strconv.Itoa(lit))
+			bw.WriteByte(' ')
+		}
+		bw.WriteString("0\n")
+	}
+	return bw.Flush()
+}
+
+// ParseSolverOutput reads the output of a SAT solver which uses the format of the SAT
+// competition: a `s SATISFIABLE` or `s UNSATISFIABLE` line, and if the formula is satisfiable,
+// `v` lines listing a literal for each variable. The model is the list of literals which are
+// true. Other lines, such as comments, are ignored.
+func ParseSolverOutput(r io.Reader) (satisfiable bool, model []int, err error) {
+	var status string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "s":
+			status = strings.Join(fields[1:], " ")
+		case "v":
+			for _, field := range fields[1:] {
+				lit, err := strconv.Atoi(field)
+				if err != nil {
+					return false, nil, fmt.Errorf("invalid literal %q in model", field)
+				}
+				if lit != 0 {
+					model = append(model, lit)
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, nil, err
+	}
+
+	switch status {
+	case "SATISFIABLE":
+		return true, model, nil
+	case "UNSATISFIABLE":
+		return false, nil, nil
+	case "":
+		return false, nil, fmt.Errorf("solver output has no status line")
+	default:
+		return false, nil, fmt.Errorf("solver returned %s", status)
+	}
+}
@@ -0,0 +1,292 @@
+package solve
+
+import (
+	"context"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// Constraint is a rule that solutions of a GridSolver must follow, such as every goal tile
+// being connected to another goal. SolveAllTiles finds solutions by solving one constraint at
+// a time, always choosing the remaining constraint which depends on the fewest unknown tiles.
+type Constraint interface {
+	// Tiles returns the tiles in g whose colors can affect whether the constraint is followed.
+	Tiles(g GridSolver) gs.TileCoordSet
+
+	// Candidates returns partial solutions which follow the constraint in g. Each solution
+	// should only assign colors to tiles in g.UnknownTiles.
+	Candidates(ctx context.Context, g GridSolver) SolutionIterator
+
+	// Check returns whether the constraint is followed in g however its unknown tiles are
+	// colored. Like GridSolver.TileStatus, it may return TileUndetermined when it is not sure.
+	Check(g GridSolver) TileStatus
+}
+
+// DefaultConstraints returns the constraints which are used by SolveAllTiles when
+// GridSolver.Constraints is nil.
+func DefaultConstraints() []Constraint {
+	return []Constraint{ForbiddenColorConstraint{}, GoalConstraint{}, DotConstraint{}, TileSolverConstraint{}, JoinConstraint{}, CrownConstraint{}}
+}
+
+// constraints returns g.Constraints, or DefaultConstraints if it is nil.
+func (g GridSolver) constraints() []Constraint {
+	if g.Constraints != nil {
+		return g.Constraints
+	}
+	return DefaultConstraints()
+}
+
+// GoalConstraint is the Constraint that each goal tile is connected to exactly one other goal by a direct path.
+type GoalConstraint struct{}
+
+// Tiles implements Constraint.
+func (GoalConstraint) Tiles(g GridSolver) gs.TileCoordSet {
+	return g.possibleBlobsOf(isType(gs.TypeGoal))
+}
+
+// Candidates implements Constraint.
+func (GoalConstraint) Candidates(ctx context.Context, g GridSolver) SolutionIterator {
+	return g.IterateGoals(ctx)
+}
+
+// Check implements Constraint.
+func (GoalConstraint) Check(g GridSolver) TileStatus {
+	return g.statusOf(isType(gs.TypeGoal))
+}
+
+// DotConstraint is the Constraint that each dot tile touches the right number of colored tiles.
+type DotConstraint struct{}
+
+// Tiles implements Constraint.
+func (DotConstraint) Tiles(g GridSolver) gs.TileCoordSet {
+	var tiles gs.TileCoordSet
+	for _, dot := range g.Grid.TilesWith(isType(gs.TypeDot1, gs.TypeDot2, gs.TypeDot3)).Slice() {
+		for _, neighbor := range g.Grid.NeighborSlice(dot.Coord) {
+			tiles.Add(neighbor.Coord)
+		}
+	}
+	return tiles
+}
+
+// Candidates implements Constraint.
+func (DotConstraint) Candidates(ctx context.Context, g GridSolver) SolutionIterator {
+	return g.IterateDots(ctx)
+}
+
+// Check implements Constraint.
+func (DotConstraint) Check(g GridSolver) TileStatus {
+	return g.statusOf(isType(gs.TypeDot1, gs.TypeDot2, gs.TypeDot3))
+}
+
+// JoinConstraint is the Constraint that each join tile's blob has the right number of special tiles.
+type JoinConstraint struct{}
+
+// Tiles implements Constraint.
+func (JoinConstraint) Tiles(g GridSolver) gs.TileCoordSet {
+	return g.possibleBlobsOf(isType(gs.TypeJoin1, gs.TypeJoin2))
+}
+
+// Candidates implements Constraint.
+func (JoinConstraint) Candidates(ctx context.Context, g GridSolver) SolutionIterator {
+	return g.IterateJoins(ctx)
+}
+
+// Check implements Constraint.
+func (JoinConstraint) Check(g GridSolver) TileStatus {
+	return g.statusOf(isType(gs.TypeJoin1, gs.TypeJoin2))
+}
+
+// CrownConstraint is the Constraint that each crown's blob contains no other crowns, and
+// that every tile with the same color as a crown is in the blob of a crown.
+type CrownConstraint struct{}
+
+// Tiles implements Constraint. Since crowns affect every tile with their color, the
+// constraint depends on every tile once there are any crowns.
+func (CrownConstraint) Tiles(g GridSolver) gs.TileCoordSet {
+	if g.Grid.TilesWith(isType(gs.TypeCrown)).Len() == 0 {
+		return gs.TileCoordSet{}
+	}
+	return g.Grid.TilesWith(func(o gs.Tile) bool { return true }).ToTileCoordSet()
+}
+
+// Candidates implements Constraint.
+func (CrownConstraint) Candidates(ctx context.Context, g GridSolver) SolutionIterator {
+	return g.IterateCrowns(ctx)
+}
+
+// Check implements Constraint.
+func (CrownConstraint) Check(g GridSolver) TileStatus {
+	return g.statusOf(isType(gs.TypeCrown))
+}
+
+// TileSolverConstraint is the Constraint that each tile whose type was registered outside
+// of gridspech, and whose TileRule is a TileSolver, is valid.
+type TileSolverConstraint struct{}
+
+// Tiles implements Constraint. Since custom rules may depend on any tile, the
+// constraint depends on every tile once there are any custom tiles.
+func (TileSolverConstraint) Tiles(g GridSolver) gs.TileCoordSet {
+	if g.Grid.TilesWith(isCustomSolvable).Len() == 0 {
+		return gs.TileCoordSet{}
+	}
+	return g.Grid.TilesWith(func(o gs.Tile) bool { return true }).ToTileCoordSet()
+}
+
+// Candidates implements Constraint.
+func (TileSolverConstraint) Candidates(ctx context.Context, g GridSolver) SolutionIterator {
+	return g.iterateCustomTiles(ctx)
+}
+
+// Check implements Constraint.
+func (TileSolverConstraint) Check(g GridSolver) TileStatus {
+	return g.statusOf(isCustomSolvable)
+}
+
+// ForbiddenColorConstraint is the Constraint that no tile has a color in GridSolver.Forbidden.
+type ForbiddenColorConstraint struct{}
+
+// Tiles implements Constraint.
+func (ForbiddenColorConstraint) Tiles(g GridSolver) gs.TileCoordSet {
+	var tiles gs.TileCoordSet
+	for coord := range g.Forbidden {
+		tiles.Add(coord)
+	}
+	return tiles
+}
+
+// Candidates implements Constraint. Each candidate gives every unknown tile in
+// g.Forbidden one of the colors that it is allowed to have.
+func (c ForbiddenColorConstraint) Candidates(ctx context.Context, g GridSolver) SolutionIterator {
+	candidates := sliceIter(gs.Assignment{})
+	for _, coord := range sortCoords(c.Tiles(g).Slice()) {
+		if !g.UnknownTiles.Has(coord) {
+			continue
+		}
+		colors := g.allowedColors(coord).Colors()
+		coord := coord
+		candidates = flatMap(ctx, candidates, func(partial gs.Assignment) SolutionIterator {
+			withColor := make([]gs.Assignment, len(colors))
+			for i, color := range colors {
+				withColor[i] = partial.Clone()
+				withColor[i].Set(coord, color)
+			}
+			return sliceIter(withColor...)
+		})
+	}
+	return candidates
+}
+
+// Check implements Constraint.
+func (ForbiddenColorConstraint) Check(g GridSolver) TileStatus {
+	status := TileSatisfied
+	for coord, forbidden := range g.Forbidden {
+		switch {
+		case g.UnknownTiles.Has(coord):
+			status = TileUndetermined
+		case forbidden.Has(g.Grid.TileAtCoord(coord).Data.Color):
+			return TileViolated
+		}
+	}
+	return status
+}
+
+// isType returns a predicate for tiles which have any of types.
+func isType(types ...gs.TileType) func(o gs.Tile) bool {
+	return func(o gs.Tile) bool {
+		for _, typ := range types {
+			if o.Data.Type == typ {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// isCustomSolvable returns if o's type was registered outside of gridspech, and can be solved with a TileSolver.
+func isCustomSolvable(o gs.Tile) bool {
+	_, ok := o.Data.Type.Rule().(TileSolver)
+	return !o.Data.Type.Builtin() && ok
+}
+
+// statusOf combines the status of each tile such that pred returns true. It is violated if any of
+// them are violated, satisfied if all of them are satisfied, and undetermined otherwise.
+func (g GridSolver) statusOf(pred func(o gs.Tile) bool) TileStatus {
+	status := TileSatisfied
+	for _, tile := range g.Grid.TilesWith(pred).Slice() {
+		switch g.TileStatus(tile.Coord) {
+		case TileViolated:
+			return TileViolated
+		case TileUndetermined:
+			status = TileUndetermined
+		}
+	}
+	return status
+}
+
+// possibleBlobsOf returns the tiles which could be in the blob of any tile such that pred returns true.
+func (g GridSolver) possibleBlobsOf(pred func(o gs.Tile) bool) gs.TileCoordSet {
+	var tiles gs.TileCoordSet
+	for _, tile := range g.Grid.TilesWith(pred).Slice() {
+		tiles.Merge(g.reachable([]gs.TileCoord{tile.Coord}, func(o gs.Tile) bool {
+			return g.UnknownTiles.Has(tile.Coord) || g.UnknownTiles.Has(o.Coord) || o.Data.Color == tile.Data.Color
+		}))
+	}
+	return tiles
+}
+
+// iterateConstraints finds solutions for constraints in g, which are merged with solved. The constraint
+// which depends on the fewest unknown tiles is solved first, and then the rest are solved for each of its
+// candidates. Once no constraints remain, solutions which do not make g valid are thrown away.
+func (g GridSolver) iterateConstraints(ctx context.Context, constraints []Constraint, solved gs.Assignment, depth int) SolutionIterator {
+	var remaining []Constraint
+	for _, c := range g.constraints() {
+		if c.Check(g) == TileViolated {
+			g.observe(EventInvalidCandidate)
+			return emptyIter()
+		}
+	}
+	for _, c := range constraints {
+		if c.Check(g) != TileSatisfied {
+			remaining = append(remaining, c)
+		}
+	}
+
+	if len(remaining) == 0 {
+		if !g.Grid.Valid() {
+			g.observe(EventInvalidCandidate)
+			return emptyIter()
+		}
+		return sliceIter(solved)
+	}
+
+	next, nextUnknown := 0, -1
+	for i, c := range remaining {
+		var unknown int
+		for _, coord := range c.Tiles(g).Slice() {
+			if g.UnknownTiles.Has(coord) {
+				unknown++
+			}
+		}
+		if nextUnknown < 0 || unknown < nextUnknown {
+			next, nextUnknown = i, unknown
+		}
+	}
+	c := remaining[next]
+	rest := append(append([]Constraint(nil), remaining[:next]...), remaining[next+1:]...)
+
+	branch := func(candidate gs.Assignment) SolutionIterator {
+		spendNodes(ctx, 1)
+		merged, ok := solved.Merged(candidate)
+		if !ok {
+			return emptyIter()
+		}
+		candidateSolved := g.Clone()
+		candidate.Apply(candidateSolved.Grid)
+		candidateSolved.UnknownTiles.RemoveAll(candidate.ToTileCoordSet())
+		return candidateSolved.iterateConstraints(ctx, rest, merged, depth+1)
+	}
+	if depth == 0 {
+		return g.parallelFlatMap(ctx, c.Candidates(ctx, g), branch)
+	}
+	return flatMap(ctx, c.Candidates(ctx, g), branch)
+}
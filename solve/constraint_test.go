@@ -0,0 +1,90 @@
+package solve_test
+
+import (
+	"context"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+// fixedColor is a Constraint that the tile at coord has color.
+type fixedColor struct {
+	coord        gs.TileCoord
+	color        gs.TileColor
+	unknownTiles *int // the number of unknown tiles when Candidates is called
+}
+
+func (c fixedColor) Tiles(g solve.GridSolver) gs.TileCoordSet {
+	return gs.NewTileCoordSet(c.coord)
+}
+
+func (c fixedColor) Candidates(ctx context.Context, g solve.GridSolver) solve.SolutionIterator {
+	*c.unknownTiles = g.UnknownTiles.Len()
+	var a gs.Assignment
+	if g.UnknownTiles.Has(c.coord) {
+		a.Set(c.coord, c.color)
+	}
+	return solve.ChanToIter(singleAssignment(a))
+}
+
+func (c fixedColor) Check(g solve.GridSolver) solve.TileStatus {
+	switch {
+	case g.UnknownTiles.Has(c.coord):
+		return solve.TileUndetermined
+	case g.Grid.TileAtCoord(c.coord).Data.Color == c.color:
+		return solve.TileSatisfied
+	default:
+		return solve.TileViolated
+	}
+}
+
+func singleAssignment(a gs.Assignment) <-chan gs.Assignment {
+	ch := make(chan gs.Assignment, 1)
+	ch <- a
+	close(ch)
+	return ch
+}
+
+func TestConstraints(t *testing.T) {
+	const level = `
+	0e  0  0
+	0   0  0e
+	`
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	all := solve.CollectSolutions(solver.IterateAllTiles(context.Background()))
+
+	var unknownTiles int
+	corner := gs.TileCoord{X: 2, Y: 1}
+	solver.Constraints = append(solve.DefaultConstraints(), fixedColor{coord: corner, color: 1, unknownTiles: &unknownTiles})
+	constrained := solve.CollectSolutions(solver.IterateAllTiles(context.Background()))
+
+	var expected []gs.Assignment
+	for _, solution := range all {
+		grid := solver.Grid.Clone()
+		solution.Apply(grid)
+		if grid.TileAtCoord(corner).Data.Color == 1 {
+			expected = append(expected, solution)
+		}
+	}
+	if len(expected) == 0 || len(expected) == len(all) {
+		t.Fatalf("expected the corner to only be colored 1 in some solutions, got %d of %d", len(expected), len(all))
+	}
+
+	var actual []gs.Assignment
+	for _, solution := range constrained {
+		solution := solution.Clone()
+		if color, ok := solution.Get(corner); !ok || color != 1 {
+			t.Errorf("expected every solution to color %v with 1, got %v", corner, solution)
+		}
+		actual = append(actual, solution)
+	}
+	if len(actual) != len(expected) {
+		t.Errorf("expected %d solutions, got %d", len(expected), len(actual))
+	}
+
+	// the constraint only depends on one unknown tile, so it should be solved first
+	if unknownTiles != solver.UnknownTiles.Len() {
+		t.Errorf("expected constraint to be solved before any others, but only %d of %d tiles were unknown", unknownTiles, solver.UnknownTiles.Len())
+	}
+}
@@ -0,0 +1,25 @@
+package solve
+
+import (
+	"context"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// sendSolution sends ts to ch. It returns false if ctx was cancelled before ts could be sent.
+func sendSolution(ctx context.Context, ch chan<- gs.Assignment, ts gs.Assignment) bool {
+	select {
+	case ch <- ts:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// singleSolution returns a closed channel containing only ts.
+func singleSolution(ts gs.Assignment) <-chan gs.Assignment {
+	ch := make(chan gs.Assignment, 1)
+	ch <- ts
+	close(ch)
+	return ch
+}
@@ -0,0 +1,86 @@
+package solve_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+// waitForGoroutines waits for the number of goroutines to drop to at most n.
+func waitForGoroutines(t *testing.T, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			buf = buf[:runtime.Stack(buf, true)]
+			t.Fatalf("expected at most %d goroutines, but there are %d:\n%s", n, runtime.NumGoroutine(), buf)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSolveAllTilesContext_cancel(t *testing.T) {
+	const level = `
+	0    0    0    0    0    0  
+	0    0e   0k   0    0    0  
+	0    0    0k   0    0    0  
+	0    0    0k   0    0    0  
+	0    0    0k   0e   0    0  
+	0j1  0e   0    0    0j1  0e 
+	`
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	ch := solver.SolveAllTilesContext(ctx)
+	if _, ok := <-ch; !ok {
+		t.Fatalf("expected a solution")
+	}
+	cancel()
+
+	waitForGoroutines(t, before)
+}
+
+func TestSolveDotsContext_timeout(t *testing.T) {
+	const level = `
+	0m2  0m2  0m2  0m2  0m2  0m2  0m2  
+	0m2  0m2  0m3  0m2  0m2  0m2  0m2  
+	0m2  0m2  0m2  0m2  0m2  0m2  0m2  
+	0m2  0m2  0m2  _    0m2  0m2  0m2  
+	0m2  0m2  0m2  0m2  0m2  0m3  0m2  
+	0m2  0m2  0m2  0m2  0m2  0m2  0m2  
+	0m2  0m2  0m2  0m2  0m2  0m2  0m2  
+	`
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	for range solver.SolveDotsContext(ctx) {
+	}
+
+	waitForGoroutines(t, before)
+}
+
+func TestShapesIterContext_cancel(t *testing.T) {
+	const level = `
+	0  0  0  
+	0  0  0  
+	0  0  0  
+	`
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	shapes, _ := solver.ShapesIterContext(ctx, gs.TileCoord{X: 1, Y: 1}, 1)
+	<-shapes
+	cancel()
+
+	waitForGoroutines(t, before)
+}
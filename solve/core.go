@@ -0,0 +1,97 @@
+package solve
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// UnsatisfiableError is returned by Diagnose when a level cannot be solved.
+type UnsatisfiableError struct {
+	// Core is a minimal set of tiles which cannot all be satisfied, sorted by X and then Y.
+	Core []gs.TileCoord
+}
+
+func (e *UnsatisfiableError) Error() string {
+	coords := make([]string, len(e.Core))
+	for i, coord := range e.Core {
+		coords[i] = coord.String()
+	}
+	return fmt.Sprintf("these %d tiles cannot all be satisfied: %s", len(e.Core), strings.Join(coords, ", "))
+}
+
+// Diagnose returns nil if g has a solution. Otherwise, it returns an *UnsatisfiableError with
+// a minimal set of tiles in g which cannot all be satisfied.
+//
+// Each tile in the set has a rule, a known color, forbidden colors, or is a paired goal. The
+// set is minimal, so if the constraints of any one of its tiles were dropped (making it a blank,
+// unknown tile with no forbidden colors or pairs), the rest of the set could be satisfied.
+func (g GridSolver) Diagnose() error {
+	return g.DiagnoseContext(context.Background())
+}
+
+// DiagnoseContext is like Diagnose, but stops once ctx is cancelled, in which case ctx.Err()
+// is returned.
+//
+// The set is found by starting with every constrained tile, and then dropping the constraints of
+// one tile at a time. If g still cannot be solved without the tile, it is removed from the set.
+func (g GridSolver) DiagnoseContext(ctx context.Context) error {
+	if _, ok, err := g.findColoring(ctx, gs.Assignment{}); err != nil || ok {
+		return err
+	}
+
+	var core gs.TileCoordSet
+	for _, col := range g.Grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type == gs.TypeHole {
+				continue
+			}
+			if tile.Data.Type != gs.TypeBlank || !g.UnknownTiles.Has(tile.Coord) || g.Forbidden[tile.Coord] != 0 {
+				core.Add(tile.Coord)
+			}
+		}
+	}
+
+	for _, coord := range sortCoords(core.Slice()) {
+		core.Remove(coord)
+		_, ok, err := g.onlyConstraining(core).findColoring(ctx, gs.Assignment{})
+		if err != nil {
+			return err
+		}
+		if ok {
+			core.Add(coord)
+		}
+	}
+	return &UnsatisfiableError{Core: sortCoords(core.Slice())}
+}
+
+// onlyConstraining returns a copy of g where only the tiles in keep are constrained. Every
+// other tile becomes a blank, unknown tile without any forbidden colors or goal pairs.
+func (g GridSolver) onlyConstraining(keep gs.TileCoordSet) GridSolver {
+	relaxed := g.Clone()
+	for _, col := range relaxed.Grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type == gs.TypeHole || keep.Has(tile.Coord) {
+				continue
+			}
+			relaxed.Grid.TileAtCoord(tile.Coord).Data.Type = gs.TypeBlank
+			relaxed.UnknownTiles.Add(tile.Coord)
+		}
+	}
+
+	relaxed.Forbidden = make(map[gs.TileCoord]ColorSet)
+	for coord, colors := range g.Forbidden {
+		if keep.Has(coord) {
+			relaxed.Forbidden[coord] = colors
+		}
+	}
+	relaxed.GoalPairs = nil
+	for _, pair := range g.GoalPairs {
+		if keep.Has(pair[0]) && keep.Has(pair[1]) {
+			relaxed.GoalPairs = append(relaxed.GoalPairs, pair)
+		}
+	}
+	return relaxed
+}
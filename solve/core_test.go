@@ -0,0 +1,93 @@
+package solve_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestGridSolver_Diagnose(t *testing.T) {
+	cases := []struct {
+		name   string
+		level  string
+		setup  func(g *solve.GridSolver) error
+		expect []gs.TileCoord
+	}{
+		{
+			name:   "solvable",
+			level:  "0e  0  0\n0  0  0e",
+			expect: nil,
+		},
+		{
+			name:   "odd goals",
+			level:  "0e  0e  0e",
+			expect: []gs.TileCoord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+		},
+		{
+			name:  "pinned dot",
+			level: "0m1  0  0e\n0  0  0e",
+			setup: func(g *solve.GridSolver) error {
+				if err := g.Pin(gs.TileCoord{X: 0, Y: 0}, 0); err != nil {
+					return err
+				}
+				return g.Pin(gs.TileCoord{X: 1, Y: 1}, 0)
+			},
+			expect: []gs.TileCoord{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}},
+		},
+		{
+			name:  "forbidden dot",
+			level: "0m1  0  0",
+			setup: func(g *solve.GridSolver) error {
+				return g.Forbid(gs.TileCoord{X: 1, Y: 0}, 1)
+			},
+			expect: []gs.TileCoord{{X: 0, Y: 0}, {X: 1, Y: 0}},
+		},
+	}
+	engines := []struct {
+		name   string
+		engine solve.Engine
+	}{
+		{name: "pipeline"},
+		{name: "sat", engine: solve.SATEngine{OnError: func(err error) { t.Error(err) }}},
+	}
+
+	for _, c := range cases {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(c.level, 2))
+		if c.setup != nil {
+			if err := c.setup(&solver); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for _, e := range engines {
+			solver.Engine = e.engine
+			err := solver.Diagnose()
+
+			var unsat *solve.UnsatisfiableError
+			if c.expect == nil {
+				if err != nil {
+					t.Errorf("%s/%s: expected no error, got %v", c.name, e.name, err)
+				}
+				continue
+			}
+			if !errors.As(err, &unsat) {
+				t.Errorf("%s/%s: expected *UnsatisfiableError, got %v", c.name, e.name, err)
+				continue
+			}
+			if !reflect.DeepEqual(unsat.Core, c.expect) {
+				t.Errorf("%s/%s: expected core %v, got %v", c.name, e.name, c.expect, unsat.Core)
+			}
+		}
+	}
+}
+
+func TestUnsatisfiableError(t *testing.T) {
+	err := &solve.UnsatisfiableError{Core: []gs.TileCoord{{X: 0, Y: 0}, {X: 2, Y: 1}}}
+	const expected = "these 2 tiles cannot all be satisfied: (0, 0), (2, 1)"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+}
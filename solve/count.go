@@ -0,0 +1,56 @@
+package solve
+
+import (
+	"context"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// coloringKey returns a key which uniquely identifies the colors of all tiles in
+// base after a has been applied to it.
+func coloringKey(base gs.Grid, a gs.Assignment) string {
+	colors := make([]byte, 0, base.Width()*base.Height())
+	for _, col := range base.Tiles {
+		for _, tile := range col {
+			colors = append(colors, byte(tile.Data.Color))
+		}
+	}
+	for _, coord := range a.Coords() {
+		color, _ := a.Get(coord)
+		colors[coord.X*base.Height()+coord.Y] = byte(color)
+	}
+	return string(colors)
+}
+
+// CountSolutions counts the number of distinct colorings which solve g. Solutions which
+// result in the same coloring of the grid are only counted once. Solving stops once limit
+// solutions have been found, or continues until all solutions are found if limit < 1.
+//
+// The returned witnesses are the distinct solutions which were counted.
+func (g GridSolver) CountSolutions(limit int) (int, []gs.Assignment) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	seen := make(map[string]struct{})
+	var witnesses []gs.Assignment
+	for solution := range g.SolveAllTilesContext(ctx) {
+		key := coloringKey(g.Grid, solution)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		witnesses = append(witnesses, solution)
+		if limit > 0 && len(witnesses) >= limit {
+			break
+		}
+	}
+	return len(witnesses), witnesses
+}
+
+// IsUnique returns if g has exactly one solution. If g has more than one solution,
+// two distinct solutions are returned as witnesses. If g has exactly one solution,
+// it is returned as the only witness.
+func (g GridSolver) IsUnique() (bool, []gs.Assignment) {
+	count, witnesses := g.CountSolutions(2)
+	return count == 1, witnesses
+}
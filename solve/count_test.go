@@ -0,0 +1,52 @@
+package solve_test
+
+import (
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/example"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestCountSolutions(t *testing.T) {
+	const level = `
+	0j1  0  0  0j1
+	`
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 3))
+
+	count, witnesses := solver.CountSolutions(0)
+	if count != 3 || len(witnesses) != 3 {
+		t.Errorf("expected 3 solutions, got %d: %v", count, witnesses)
+	}
+
+	count, witnesses = solver.CountSolutions(2)
+	if count != 2 || len(witnesses) != 2 {
+		t.Errorf("expected limit of 2 solutions, got %d: %v", count, witnesses)
+	}
+	if witnesses[0].Eq(witnesses[1]) {
+		t.Errorf("expected distinct witnesses, got %v", witnesses)
+	}
+}
+
+func TestIsUnique(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Level    string
+		Expected bool
+		Count    int
+	}{
+		{"unique", example.LevelA1, true, 1},
+		{"multiple", `0j1  0  0  0j1`, false, 2},
+		{"unsolvable", `0m3  0`, false, 0},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			solver := solve.NewGridSolver(gs.MakeGridFromString(testCase.Level, 2))
+			unique, witnesses := solver.IsUnique()
+			if unique != testCase.Expected || len(witnesses) != testCase.Count {
+				t.Errorf("expected unique=%v with %d witnesses, got unique=%v with %v", testCase.Expected, testCase.Count, unique, witnesses)
+			}
+		})
+	}
+}
@@ -1,11 +1,23 @@
 package solve
 
 import (
+	"context"
+
 	gs "github.com/deanveloper/gridspech-go"
 )
 
 // SolveCrowns will return a channel of solutions for all the crown tiles in g.
-func (g GridSolver) SolveCrowns() <-chan gs.TileSet {
+func (g GridSolver) SolveCrowns() <-chan gs.Assignment {
+	return g.SolveCrownsContext(context.Background())
+}
+
+// SolveCrownsContext is like SolveCrowns, but stops once ctx is cancelled.
+func (g GridSolver) SolveCrownsContext(ctx context.Context) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IterateCrowns(ctx))
+}
+
+// IterateCrowns is like SolveCrownsContext, but returns an iterator.
+func (g GridSolver) IterateCrowns(ctx context.Context) SolutionIterator {
 
 	// get all crown tiles
 	crownTiles := g.Grid.TilesWith(func(o gs.Tile) bool {
@@ -13,63 +25,56 @@ func (g GridSolver) SolveCrowns() <-chan gs.TileSet {
 	}).Slice()
 
 	if len(crownTiles) == 0 {
-		ch := make(chan gs.TileSet, 1)
-		ch <- gs.NewTileSet()
-		close(ch)
-		return ch
+		return sliceIter(gs.Assignment{})
 	}
 
-	tilesToSolutions := make([]<-chan gs.TileSet, len(crownTiles))
+	tilesToSolutions := make([]SolutionIterator, len(crownTiles))
 	for i, tile := range crownTiles {
-		tilesToSolutions[i] = g.SolveCrown(tile.Coord)
+		tilesToSolutions[i] = g.IterateCrown(ctx, tile.Coord)
 	}
 
 	// now merge them all together
 	for i := 1; i < len(crownTiles); i++ {
-		mergedIter := MergeSolutionsIters(tilesToSolutions[i-1], tilesToSolutions[i])
-		tilesToSolutions[i] = mergedIter
+		tilesToSolutions[i] = g.merge(ctx, tilesToSolutions[i-1], tilesToSolutions[i])
 	}
 
 	return tilesToSolutions[len(tilesToSolutions)-1]
 }
 
 // SolveCrown returns a channel of solutions for a crown at the given coordinate.
-func (g GridSolver) SolveCrown(crown gs.TileCoord) <-chan gs.TileSet {
-
-	crownIter := make(chan gs.TileSet, 50)
-
-	go func() {
-		defer close(crownIter)
-
-		for c := 0; c < g.Grid.MaxColors; c++ {
+func (g GridSolver) SolveCrown(crown gs.TileCoord) <-chan gs.Assignment {
+	return g.SolveCrownContext(context.Background(), crown)
+}
 
-			shapesCh, pruneCh := g.ShapesIter(crown, gs.TileColor(c))
+// SolveCrownContext is like SolveCrown, but stops once ctx is cancelled.
+func (g GridSolver) SolveCrownContext(ctx context.Context, crown gs.TileCoord) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IterateCrown(ctx, crown))
+}
 
-			for shape := range shapesCh {
-				prune := shouldPruneCrown(g, crown, shape, gs.TileColor(c))
-				pruneCh <- prune
-				if !prune {
-					for decorated := range decorateSetBorder(g, gs.TileColor(c), shape) {
-						crownIter <- decorated
-					}
-				}
+// IterateCrown is like SolveCrownContext, but returns an iterator.
+func (g GridSolver) IterateCrown(ctx context.Context, crown gs.TileCoord) SolutionIterator {
+	return filterAllowed(ctx, g, concatIter(ctx, g.Grid.MaxColors, func(c int) SolutionIterator {
+		shapes := g.IterateShapes(ctx, crown, gs.TileColor(c))
+		return flatMap(ctx, shapes, func(shape gs.Assignment) SolutionIterator {
+			if shouldPruneCrown(g, crown, shape, gs.TileColor(c)) {
+				shapes.Prune()
+				return emptyIter()
 			}
-		}
-	}()
-
-	return crownIter
+			return decorateSetBorder(ctx, g, gs.TileColor(c), shape)
+		})
+	}))
 }
 
 // prune if:
 // - this shape contains a separate crown of the same color
 // - if there is a goal tile, the shape must be a path
-func shouldPruneCrown(g GridSolver, crown gs.TileCoord, shape gs.TileSet, color gs.TileColor) bool {
-	shapeAsSlice := shape.Slice()
+func shouldPruneCrown(g GridSolver, crown gs.TileCoord, shape gs.Assignment, color gs.TileColor) bool {
 	shapeCoords := shape.ToTileCoordSet()
 
 	var containsGoalTile bool
 	var containsTrineighborTile bool
-	for _, tile := range shapeAsSlice {
+	for _, coord := range shape.Coords() {
+		tile := *g.Grid.TileAtCoord(coord)
 		if tile.Data.Type == gs.TypeCrown && tile.Coord != crown {
 			return true
 		}
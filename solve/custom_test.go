@@ -0,0 +1,62 @@
+package solve_test
+
+import (
+	"context"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+// darkRule is a tile which may not touch any colored tiles.
+type darkRule struct{}
+
+func (darkRule) Name() string  { return "Dark" }
+func (darkRule) Token() string { return "d" }
+func (darkRule) Violations(g gs.Grid, t gs.Tile) []gs.Violation {
+	colored := g.NeighborSliceWith(t.Coord, func(o gs.Tile) bool {
+		return o.Data.Color != gs.ColorNone
+	})
+	if len(colored) == 0 {
+		return nil
+	}
+	return []gs.Violation{{Tile: t, Kind: gs.ViolationRule, Message: "dark tile touches a colored tile"}}
+}
+
+func (darkRule) SolveTile(ctx context.Context, g solve.GridSolver, t gs.Tile) <-chan gs.Assignment {
+	ch := make(chan gs.Assignment, 1)
+	defer close(ch)
+
+	var solution gs.Assignment
+	for _, neighbor := range g.Grid.NeighborSlice(t.Coord) {
+		if !g.UnknownTiles.Has(neighbor.Coord) {
+			if neighbor.Data.Color != gs.ColorNone {
+				return ch
+			}
+			continue
+		}
+		solution.Set(neighbor.Coord, gs.ColorNone)
+	}
+	ch <- solution
+	return ch
+}
+
+var _ solve.TileSolver = darkRule{}
+
+func init() {
+	gs.RegisterTileRule(darkRule{})
+}
+
+func TestSolveAllTiles_customRule(t *testing.T) {
+	const level = `
+	0    0d   0 
+	0    0m2  0 
+	`
+	solutions := []string{
+		"000|101",
+		"010|100",
+		"010|001",
+	}
+
+	testSolveAllTilesAbstract(t, level, solutions, 2)
+}
@@ -78,7 +78,7 @@ func TestDots_noDuplicates(t *testing.T) {
 	0    0m2  0m1  0m2  0
 	0m2  0m1  0m2  0m1  0m2
 	`
-	var prevSolutions []gs.TileSet
+	var prevSolutions []gs.Assignment
 	for solution := range solve.NewGridSolver(gs.MakeGridFromString(level, 2)).SolveDots() {
 		for _, prev := range prevSolutions {
 			if prev.Eq(solution) {
@@ -1,44 +1,66 @@
 package solve
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	"github.com/deanveloper/gridspech-go"
 	gs "github.com/deanveloper/gridspech-go"
 )
 
 // SolveDots will return a slice of solutions for all of the dot tiles in g.
-func (g GridSolver) SolveDots() <-chan gs.TileSet {
+func (g GridSolver) SolveDots() <-chan gs.Assignment {
+	return g.SolveDotsContext(context.Background())
+}
+
+// SolveDotsContext is like SolveDots, but stops once ctx is cancelled.
+func (g GridSolver) SolveDotsContext(ctx context.Context) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IterateDots(ctx))
+}
+
+// IterateDots is like SolveDotsContext, but returns an iterator.
+func (g GridSolver) IterateDots(ctx context.Context) SolutionIterator {
 
-	// get all dot-related tiles
+	// get all dot-related tiles, in the same order every time so that they are merged in the same order
 	dotTiles := g.Grid.TilesWith(func(o gs.Tile) bool {
 		return o.Data.Type == gridspech.TypeDot1 || o.Data.Type == gridspech.TypeDot2 || o.Data.Type == gridspech.TypeDot3
 	}).Slice()
+	sort.Slice(dotTiles, func(i, j int) bool {
+		a, b := dotTiles[i].Coord, dotTiles[j].Coord
+		return a.X < b.X || a.X == b.X && a.Y < b.Y
+	})
 
 	if len(dotTiles) == 0 {
-		ch := make(chan gs.TileSet, 1)
-		ch <- gs.NewTileSet()
-		close(ch)
-		return ch
+		return sliceIter(gs.Assignment{})
 	}
 
-	tilesToSolutions := make([]<-chan gs.TileSet, len(dotTiles))
+	tilesToSolutions := make([]SolutionIterator, len(dotTiles))
 	for i, tile := range dotTiles {
-		tilesToSolutions[i] = g.SolveDot(tile)
+		tilesToSolutions[i] = g.IterateDot(ctx, tile)
 	}
 
 	// now merge them all together
 	for i := 1; i < len(dotTiles); i++ {
-		mergedIter := MergeSolutionsIters(tilesToSolutions[i-1], tilesToSolutions[i])
-		uniqueIter := filterUnique(mergedIter)
-		tilesToSolutions[i] = uniqueIter
+		mergedIter := g.merge(ctx, tilesToSolutions[i-1], tilesToSolutions[i])
+		tilesToSolutions[i] = filterUnique(ctx, mergedIter)
 	}
 
 	return tilesToSolutions[len(dotTiles)-1]
 }
 
 // SolveDot returns a channel of solutions for a given dot tile.
-func (g GridSolver) SolveDot(t gs.Tile) <-chan gs.TileSet {
+func (g GridSolver) SolveDot(t gs.Tile) <-chan gs.Assignment {
+	return g.SolveDotContext(context.Background(), t)
+}
+
+// SolveDotContext is like SolveDot, but stops once ctx is cancelled.
+func (g GridSolver) SolveDotContext(ctx context.Context, t gs.Tile) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IterateDot(ctx, t))
+}
+
+// IterateDot is like SolveDotContext, but returns an iterator.
+func (g GridSolver) IterateDot(ctx context.Context, t gs.Tile) SolutionIterator {
 	var numDots int
 
 	switch t.Data.Type {
@@ -55,40 +77,33 @@ func (g GridSolver) SolveDot(t gs.Tile) <-chan gs.TileSet {
 	knownEnabledTiles := g.Grid.NeighborSetWith(t.Coord, func(o gs.Tile) bool {
 		return o.Data.Color != gs.ColorNone && !g.UnknownTiles.Has(o.Coord)
 	})
+	numDots -= knownEnabledTiles.Len()
 
-	return g.solveDotRecur(t.Coord, gs.NewTileCoordSet(), numDots-knownEnabledTiles.Len())
-}
-
-func (g GridSolver) solveDotRecur(
-	t gs.TileCoord,
-	tilesBeingUsed gs.TileCoordSet,
-	numDots int,
-) <-chan gs.TileSet {
-
-	ch := make(chan gs.TileSet, 4)
-
-	go func() {
-
-		defer close(ch)
+	if numDots < 0 {
+		return emptyIter()
+	}
+	if numDots == 0 {
+		return sliceIter(gs.Assignment{})
+	}
 
-		if numDots < 0 {
-			return
-		}
-		if numDots == 0 {
-			ch <- gs.NewTileSet()
-			return
-		}
+	unknownNeighbors := g.Grid.NeighborSliceWith(t.Coord, func(o gs.Tile) bool {
+		return g.UnknownTiles.Has(o.Coord)
+	})
 
-		unknownNeighbors := g.Grid.NeighborSliceWith(t, func(o gs.Tile) bool {
-			return g.UnknownTiles.Has(o.Coord) && !tilesBeingUsed.Has(o.Coord)
-		})
+	// if there are not enough unknown neighbors to fulfil this dot, then there are no solutions
+	if numDots > len(unknownNeighbors) {
+		return emptyIter()
+	}
 
-		// if there are not enough unknown neighbors to fulfil this dot, then there are no solutions
-		if numDots > len(unknownNeighbors) {
-			return
-		}
+	perms := newPermutationIter(g.Grid.MaxColors, len(unknownNeighbors))
+	return filterAllowed(ctx, g, iterFunc(func() (gs.Assignment, bool) {
+		for ctx.Err() == nil {
+			perm, ok := perms.next()
+			if !ok {
+				break
+			}
+			spendNodes(ctx, 1)
 
-		for perm := range Permutation(g.Grid.MaxColors, len(unknownNeighbors)) {
 			var numNonZero int
 			for _, i := range perm {
 				if i > 0 {
@@ -99,15 +114,25 @@ func (g GridSolver) solveDotRecur(
 				continue
 			}
 
-			var result gs.TileSet
-			for i, c := range perm {
-				tCopy := unknownNeighbors[i]
-				tCopy.Data.Color = gs.TileColor(c)
-				result.Add(tCopy)
+			// a tile may neighbor the dot more than once through arrows, but can only have one color
+			result, ok := assignPermutation(unknownNeighbors, perm)
+			if !ok {
+				continue
 			}
-			ch <- result
+			return result, true
 		}
-	}()
+		return gs.Assignment{}, false
+	}))
+}
 
-	return ch
+// assignPermutation assigns perm[i] to the color of tiles[i]. It returns false if
+// the same tile would be assigned different colors.
+func assignPermutation(tiles []gs.Tile, perm []int) (gs.Assignment, bool) {
+	var result gs.Assignment
+	for i, c := range perm {
+		if err := result.Set(tiles[i].Coord, gs.TileColor(c)); err != nil {
+			return gs.Assignment{}, false
+		}
+	}
+	return result, true
 }
@@ -0,0 +1,474 @@
+package solve
+
+import (
+	"fmt"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// Encoding is a CNF formula which is satisfiable exactly when a GridSolver can be solved.
+// Each satisfying assignment of the formula corresponds to a solution.
+//
+// For each tile and color, there is a variable which is true if the tile has that color.
+// The rest of the variables are used to describe the tiles' rules:
+//   - Dots use a sequential counter to count how many of their neighbors are colored.
+//   - The blob of each goal, crown, and join is found by tracking which tiles can be reached
+//     from it in at most k steps, for each k up to the number of tiles.
+//   - Goals and the tiles in their blobs have their number of same-colored neighbors counted.
+//     Goal blobs also have their number of goals counted, and join blobs have their number
+//     of special tiles counted.
+//   - Every tile with the same color as a crown must be in the blob of some crown.
+//   - Unknown tiles may not have their forbidden colors, and paired goals must be in
+//     each other's blobs.
+type Encoding struct {
+	CNF
+
+	grid  gs.Grid
+	tiles []gs.TileCoord
+
+	// colorVars[x][y] is the variable for the tile at (x, y) having color 0.
+	// The variable for color c is colorVars[x][y] + c.
+	colorVars [][]int
+	trueVar   int
+	sameVars  map[[2]gs.TileCoord]int
+}
+
+// EncodeCNF encodes g as a CNF formula. An error is returned if g contains tiles whose types
+// were registered outside of gridspech, as their rules cannot be encoded.
+func (g GridSolver) EncodeCNF() (*Encoding, error) {
+	e := &Encoding{
+		grid:      g.Grid,
+		colorVars: make([][]int, g.Grid.Width()),
+		sameVars:  make(map[[2]gs.TileCoord]int),
+	}
+	e.trueVar = e.NewVar()
+	e.AddClause(e.trueVar)
+
+	// each tile has exactly one color, and tiles which are not unknown keep their color
+	for x, col := range g.Grid.Tiles {
+		e.colorVars[x] = make([]int, len(col))
+		for y, tile := range col {
+			if tile.Data.Type == gs.TypeHole {
+				continue
+			}
+			if !tile.Data.Type.Builtin() {
+				return nil, fmt.Errorf("cannot encode tile %v with type %v", tile.Coord, tile.Data.Type)
+			}
+
+			lits := make([]int, g.Grid.MaxColors)
+			for c := range lits {
+				lits[c] = e.NewVar()
+			}
+			e.colorVars[x][y] = lits[0]
+			e.exactly(lits, 1)
+
+			if g.UnknownTiles.Has(tile.Coord) {
+				e.tiles = append(e.tiles, tile.Coord)
+				for _, c := range g.Forbidden[tile.Coord].Colors() {
+					if int(c) < g.Grid.MaxColors {
+						e.AddClause(-e.ColorVar(tile.Coord, c))
+					}
+				}
+			} else {
+				e.AddClause(e.ColorVar(tile.Coord, tile.Data.Color))
+			}
+		}
+	}
+
+	e.encodeDots()
+	e.encodeGoals(g.GoalPairs)
+	e.encodeCrowns()
+	e.encodeJoins()
+	return e, nil
+}
+
+// ColorVar returns the variable which is true if the tile at coord has color c.
+func (e *Encoding) ColorVar(coord gs.TileCoord, c gs.TileColor) int {
+	return e.colorVars[coord.X][coord.Y] + int(c)
+}
+
+// Tiles returns the unknown tiles, whose colors are decided by the formula.
+func (e *Encoding) Tiles() []gs.TileCoord {
+	return e.tiles
+}
+
+// Decode returns the solution described by model, which is a list of the literals that are true.
+// The solution contains every tile returned by Tiles.
+func (e *Encoding) Decode(model []int) (gs.Assignment, error) {
+	trueVars := make(map[int]bool, len(model))
+	for _, lit := range model {
+		if lit > 0 {
+			trueVars[lit] = true
+		}
+	}
+
+	var solution gs.Assignment
+	for _, coord := range e.tiles {
+		var found bool
+		for c := 0; c < e.grid.MaxColors; c++ {
+			if !trueVars[e.ColorVar(coord, gs.TileColor(c))] {
+				continue
+			}
+			if found {
+				return gs.Assignment{}, fmt.Errorf("model gives tile %v more than one color", coord)
+			}
+			found = true
+			solution.Set(coord, gs.TileColor(c))
+		}
+		if !found {
+			return gs.Assignment{}, fmt.Errorf("model does not give tile %v a color", coord)
+		}
+	}
+	return solution, nil
+}
+
+// Block adds a clause to the formula so that solution is no longer a satisfying assignment.
+func (e *Encoding) Block(solution gs.Assignment) {
+	e.AddClause(e.BlockingClause(solution)...)
+}
+
+// BlockingClause returns a clause which is false only when the tiles have the colors in solution.
+func (e *Encoding) BlockingClause(solution gs.Assignment) []int {
+	var clause []int
+	for _, coord := range solution.Coords() {
+		color, _ := solution.Get(coord)
+		clause = append(clause, -e.ColorVar(coord, color))
+	}
+	return clause
+}
+
+// dots must touch exactly n colored tiles.
+func (e *Encoding) encodeDots() {
+	for _, col := range e.grid.Tiles {
+		for _, tile := range col {
+			var n int
+			switch tile.Data.Type {
+			case gs.TypeDot1:
+				n = 1
+			case gs.TypeDot2:
+				n = 2
+			case gs.TypeDot3:
+				n = 3
+			default:
+				continue
+			}
+
+			var colored []int
+			for _, neighbor := range e.grid.NeighborSlice(tile.Coord) {
+				colored = append(colored, -e.ColorVar(neighbor.Coord, gs.ColorNone))
+			}
+			e.exactly(colored, n)
+		}
+	}
+}
+
+// the blob of each goal must contain exactly two goals, which have exactly one same-colored
+// neighbor, and the rest of the tiles in the blob must have exactly two same-colored neighbors.
+// Each pair of goals in pairs must be in the same blob.
+func (e *Encoding) encodeGoals(pairs [][2]gs.TileCoord) {
+	goals := e.tilesOfType(gs.TypeGoal)
+
+	// onPath[t] is true if t is in the blob of any goal
+	onPath := make(map[gs.TileCoord]int)
+	for _, goal := range goals {
+		blob := e.blobVars(goal)
+		for _, pair := range pairs {
+			if pair[0] == goal {
+				e.AddClause(blob[pair[1]])
+			}
+		}
+
+		var goalsInBlob []int
+		for _, other := range goals {
+			goalsInBlob = append(goalsInBlob, blob[other])
+		}
+		e.exactly(goalsInBlob, 2)
+
+		for _, coord := range sortCoords(coordKeys(blob)) {
+			inBlob := blob[coord]
+			if inBlob == -e.trueVar {
+				continue
+			}
+			if _, ok := onPath[coord]; !ok {
+				onPath[coord] = e.NewVar()
+			}
+			e.AddClause(-inBlob, onPath[coord])
+		}
+	}
+
+	for _, coord := range sortCoords(coordKeys(onPath)) {
+		want := 2
+		if e.grid.TileAtCoord(coord).Data.Type == gs.TypeGoal {
+			want = 1
+		}
+		var same []int
+		for _, neighbor := range e.grid.NeighborSlice(coord) {
+			same = append(same, e.same(coord, neighbor.Coord))
+		}
+		e.exactly(same, want, onPath[coord])
+	}
+}
+
+// crown blobs may not contain other crowns, and every tile with the same color as a crown
+// must be in the blob of a crown with that color.
+func (e *Encoding) encodeCrowns() {
+	crowns := e.tilesOfType(gs.TypeCrown)
+	if len(crowns) == 0 {
+		return
+	}
+
+	blobs := make([]map[gs.TileCoord]int, len(crowns))
+	for i, crown := range crowns {
+		blobs[i] = e.blobVars(crown)
+		for _, other := range crowns {
+			if other != crown {
+				e.AddClause(-blobs[i][other])
+			}
+		}
+	}
+
+	for _, col := range e.grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type == gs.TypeHole {
+				continue
+			}
+
+			var inCrownBlobs []int
+			for _, blob := range blobs {
+				inCrownBlobs = append(inCrownBlobs, blob[tile.Coord])
+			}
+			covered := e.or(inCrownBlobs...)
+
+			for _, crown := range crowns {
+				for c := 0; c < e.grid.MaxColors; c++ {
+					color := gs.TileColor(c)
+					e.AddClause(-e.ColorVar(tile.Coord, color), -e.ColorVar(crown, color), covered)
+				}
+			}
+		}
+	}
+}
+
+// join blobs must contain exactly n other special tiles.
+func (e *Encoding) encodeJoins() {
+	for _, col := range e.grid.Tiles {
+		for _, tile := range col {
+			var n int
+			switch tile.Data.Type {
+			case gs.TypeJoin1:
+				n = 1
+			case gs.TypeJoin2:
+				n = 2
+			default:
+				continue
+			}
+
+			blob := e.blobVars(tile.Coord)
+			var special []int
+			for _, coord := range sortCoords(coordKeys(blob)) {
+				if e.grid.TileAtCoord(coord).Data.Type != gs.TypeBlank {
+					special = append(special, blob[coord])
+				}
+			}
+			e.exactly(special, n+1)
+		}
+	}
+}
+
+func (e *Encoding) tilesOfType(typ gs.TileType) []gs.TileCoord {
+	var coords []gs.TileCoord
+	for _, col := range e.grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type == typ {
+				coords = append(coords, tile.Coord)
+			}
+		}
+	}
+	return coords
+}
+
+// blobVars returns a literal for each non-hole tile which is true if the tile is in the blob
+// of start. A tile is in the blob if it can be reached in at most k steps between
+// same-colored neighbors, where k is one less than the number of tiles which are connected
+// to start through non-hole tiles, since a path within the blob never visits a tile twice.
+//
+// Each step makes new variables for the tiles which it can reach, so a blob uses O(k·n)
+// auxiliary variables, where n is the number of tiles that can be reached from start.
+// The steps cannot stop early once the blob stops growing, as that depends on the colors.
+func (e *Encoding) blobVars(start gs.TileCoord) map[gs.TileCoord]int {
+	// previous[t] are the tiles which have t as a neighbor, and following[t] are the neighbors of t
+	previous := make(map[gs.TileCoord][]gs.TileCoord)
+	following := make(map[gs.TileCoord][]gs.TileCoord)
+	reached := make(map[gs.TileCoord]int)
+	for _, col := range e.grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type == gs.TypeHole {
+				continue
+			}
+			reached[tile.Coord] = -e.trueVar
+			for _, neighbor := range e.grid.NeighborSet(tile.Coord).Slice() {
+				if neighbor.Coord != tile.Coord {
+					previous[neighbor.Coord] = append(previous[neighbor.Coord], tile.Coord)
+					following[tile.Coord] = append(following[tile.Coord], neighbor.Coord)
+				}
+			}
+		}
+	}
+	reached[start] = e.trueVar
+
+	coords := sortCoords(coordKeys(reached))
+	connected := map[gs.TileCoord]bool{start: true}
+	for queue := []gs.TileCoord{start}; len(queue) > 0; queue = queue[1:] {
+		for _, coord := range following[queue[0]] {
+			if !connected[coord] {
+				connected[coord] = true
+				queue = append(queue, coord)
+			}
+		}
+	}
+	for step := 1; step < len(connected); step++ {
+		next := make(map[gs.TileCoord]int, len(reached))
+		for _, coord := range coords {
+			lits := []int{reached[coord]}
+			for _, prev := range previous[coord] {
+				lits = append(lits, e.and(reached[prev], e.same(prev, coord)))
+			}
+			next[coord] = e.or(lits...)
+		}
+		reached = next
+	}
+	return reached
+}
+
+// same returns a variable which is true if the tiles at a and b have the same color.
+func (e *Encoding) same(a, b gs.TileCoord) int {
+	if a == b {
+		return e.trueVar
+	}
+	if a.X > b.X || a.X == b.X && a.Y > b.Y {
+		a, b = b, a
+	}
+	if v, ok := e.sameVars[[2]gs.TileCoord{a, b}]; ok {
+		return v
+	}
+
+	v := e.NewVar()
+	for c := 0; c < e.grid.MaxColors; c++ {
+		color := gs.TileColor(c)
+		e.AddClause(-e.ColorVar(a, color), -e.ColorVar(b, color), v)
+		e.AddClause(-v, -e.ColorVar(a, color), e.ColorVar(b, color))
+	}
+	e.sameVars[[2]gs.TileCoord{a, b}] = v
+	return v
+}
+
+// and returns a literal which is true if both a and b are true.
+func (e *Encoding) and(a, b int) int {
+	switch {
+	case a == -e.trueVar || b == -e.trueVar:
+		return -e.trueVar
+	case a == e.trueVar:
+		return b
+	case b == e.trueVar:
+		return a
+	}
+	v := e.NewVar()
+	e.AddClause(-v, a)
+	e.AddClause(-v, b)
+	e.AddClause(v, -a, -b)
+	return v
+}
+
+// or returns a literal which is true if any of lits are true.
+func (e *Encoding) or(lits ...int) int {
+	var remaining []int
+	for _, lit := range lits {
+		if lit == e.trueVar {
+			return e.trueVar
+		}
+		if lit != -e.trueVar {
+			remaining = append(remaining, lit)
+		}
+	}
+	switch len(remaining) {
+	case 0:
+		return -e.trueVar
+	case 1:
+		return remaining[0]
+	}
+
+	v := e.NewVar()
+	e.AddClause(append([]int{-v}, remaining...)...)
+	for _, lit := range remaining {
+		e.AddClause(v, -lit)
+	}
+	return v
+}
+
+// exactly requires that exactly k of lits are true. If any conditions are given, the
+// requirement only applies when all of the conditions are true.
+func (e *Encoding) exactly(lits []int, k int, conditions ...int) {
+	e.atMost(lits, k, conditions...)
+
+	negated := make([]int, len(lits))
+	for i, lit := range lits {
+		negated[i] = -lit
+	}
+	e.atMost(negated, len(lits)-k, conditions...)
+}
+
+// atMost requires that at most k of lits are true, using a sequential counter. If any
+// conditions are given, the requirement only applies when all of the conditions are true.
+func (e *Encoding) atMost(lits []int, k int, conditions ...int) {
+	clause := func(clauseLits ...int) {
+		for _, cond := range conditions {
+			clauseLits = append(clauseLits, -cond)
+		}
+		e.AddClause(clauseLits...)
+	}
+
+	switch {
+	case k >= len(lits):
+		return
+	case k < 0:
+		clause()
+		return
+	case k == 0:
+		for _, lit := range lits {
+			clause(-lit)
+		}
+		return
+	}
+
+	// counts[i][j] is true if at least j+1 of lits[:i+1] are true
+	counts := make([][]int, len(lits)-1)
+	for i := range counts {
+		counts[i] = make([]int, k)
+		for j := range counts[i] {
+			counts[i][j] = e.NewVar()
+		}
+	}
+
+	clause(-lits[0], counts[0][0])
+	for j := 1; j < k; j++ {
+		clause(-counts[0][j])
+	}
+	for i := 1; i < len(lits)-1; i++ {
+		clause(-lits[i], counts[i][0])
+		clause(-counts[i-1][0], counts[i][0])
+		for j := 1; j < k; j++ {
+			clause(-lits[i], -counts[i-1][j-1], counts[i][j])
+			clause(-counts[i-1][j], counts[i][j])
+		}
+		clause(-lits[i], -counts[i-1][k-1])
+	}
+	clause(-lits[len(lits)-1], -counts[len(lits)-2][k-1])
+}
+
+func coordKeys(m map[gs.TileCoord]int) []gs.TileCoord {
+	coords := make([]gs.TileCoord, 0, len(m))
+	for coord := range m {
+		coords = append(coords, coord)
+	}
+	return coords
+}
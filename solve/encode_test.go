@@ -0,0 +1,307 @@
+package solve_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/example"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+// the formula should be satisfiable with a coloring exactly when the coloring is valid.
+func TestEncodeCNF_matchesValid(t *testing.T) {
+	levels := []string{
+		example.LevelA1,
+		"0m2  0  0\n0  0  0m1",
+		"0m1<  0  1/",
+		"0j1  0  0  0j1",
+		"0j2  0j1  0\n0  0  0j1",
+		"0k  0  0\n0  0  0k",
+		"1/k  0  0\n0  _  0k",
+		"0e   0  0e\n0e   0  0e",
+		"0e^  0   0e\n0    0   0",
+	}
+
+	for _, level := range levels {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+		enc, err := solver.EncodeCNF()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tiles := enc.Tiles()
+		for mask := 0; mask < 1<<len(tiles); mask++ {
+			colored := solver.Grid.Clone()
+			clauses := append([][]int(nil), enc.Clauses...)
+			for i, coord := range tiles {
+				color := gs.TileColor(mask >> i & 1)
+				colored.TileAtCoord(coord).Data.Color = color
+				clauses = append(clauses, []int{enc.ColorVar(coord, color)})
+			}
+
+			_, sat := solveCNF(enc.NumVars, clauses)
+			if sat != colored.Valid() {
+				t.Errorf("expected satisfiable=%v for\n%v", colored.Valid(), colored)
+			}
+		}
+	}
+}
+
+func TestEncoding_Decode(t *testing.T) {
+	solver := solve.NewGridSolver(gs.MakeGridFromString(example.LevelA1, 2))
+	enc, err := solver.EncodeCNF()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model, sat := solveCNF(enc.NumVars, enc.Clauses)
+	if !sat {
+		t.Fatal("expected formula to be satisfiable")
+	}
+	solution, err := enc.Decode(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := assignmentFromString(solver.Grid, " 111")
+	if !solution.Eq(expected) {
+		t.Errorf("expected %v, got %v", expected, solution)
+	}
+
+	enc.Block(solution)
+	if _, sat := solveCNF(enc.NumVars, enc.Clauses); sat {
+		t.Errorf("expected formula to be unsatisfiable after blocking the only solution")
+	}
+
+	if _, err := enc.Decode(nil); err == nil {
+		t.Errorf("expected error for empty model")
+	}
+}
+
+func TestWriteDIMACS(t *testing.T) {
+	var f solve.CNF
+	a, b := f.NewVar(), f.NewVar()
+	f.AddClause(a, -b)
+	f.AddClause(b)
+
+	var sb strings.Builder
+	if err := f.WriteDIMACS(&sb); err != nil {
+		t.Fatal(err)
+	}
+	const expected = "p cnf 2 2\n1 -2 0\n2 0\n"
+	if sb.String() != expected {
+		t.Errorf("expected %q, got %q", expected, sb.String())
+	}
+}
+
+func TestParseSolverOutput(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Output string
+		Sat    bool
+		Model  []int
+		Err    bool
+	}{
+		{"sat", "c comment\ns SATISFIABLE\nv 1 -2\nv 3 0\n", true, []int{1, -2, 3}, false},
+		{"unsat", "s UNSATISFIABLE\n", false, nil, false},
+		{"unknown", "s UNKNOWN\n", false, nil, true},
+		{"missing", "c nothing here\n", false, nil, true},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			sat, model, err := solve.ParseSolverOutput(strings.NewReader(testCase.Output))
+			if (err != nil) != testCase.Err || sat != testCase.Sat || fmt.Sprint(model) != fmt.Sprint(testCase.Model) {
+				t.Errorf("expected (%v, %v, err=%v), got (%v, %v, %v)", testCase.Sat, testCase.Model, testCase.Err, sat, model, err)
+			}
+		})
+	}
+}
+
+func TestExternalEngine(t *testing.T) {
+	os.Setenv("GS_TEST_SAT_SOLVER", "1")
+	defer os.Unsetenv("GS_TEST_SAT_SOLVER")
+
+	var solverErr error
+	engine := solve.ExternalEngine{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=TestHelperSATSolver", "--"},
+		OnError: func(err error) { solverErr = err },
+	}
+
+	const level = `0j1  0  0  0j1`
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 3))
+	expected := solvedGrids(solver)
+
+	solver.Engine = engine
+	actual := solvedGrids(solver)
+	if solverErr != nil {
+		t.Fatal(solverErr)
+	}
+	if len(actual) != len(expected) {
+		t.Errorf("expected %d solutions, got %d", len(expected), len(actual))
+	}
+	for grid := range actual {
+		if _, ok := expected[grid]; !ok {
+			t.Errorf("incorrect solution\n%v", grid)
+		}
+	}
+}
+
+func TestExternalEngine_missingCommand(t *testing.T) {
+	var solverErr error
+	engine := solve.ExternalEngine{
+		Command: "gridspech-solver-which-does-not-exist",
+		OnError: func(err error) { solverErr = err },
+	}
+	for range engine.SolveAll(context.Background(), solve.NewGridSolver(gs.MakeGridFromString(example.LevelA1, 2))) {
+		t.Errorf("expected no solutions")
+	}
+	if solverErr == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+// TestHelperSATSolver is not a real test. It is run by TestExternalEngine as a SAT solver.
+func TestHelperSATSolver(t *testing.T) {
+	if os.Getenv("GS_TEST_SAT_SOLVER") != "1" {
+		return
+	}
+
+	numVars, clauses, err := readDIMACS(os.Args[len(os.Args)-1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	model, sat := solveCNF(numVars, clauses)
+	if !sat {
+		fmt.Println("s UNSATISFIABLE")
+		os.Exit(20)
+	}
+	fmt.Println("s SATISFIABLE")
+	fmt.Print("v")
+	for _, lit := range model {
+		fmt.Print(" ", lit)
+	}
+	fmt.Println(" 0")
+	os.Exit(10)
+}
+
+func readDIMACS(path string) (numVars int, clauses [][]int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	var clause []int
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "p cnf") {
+			numVars, err = strconv.Atoi(strings.Fields(line)[2])
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			lit, err := strconv.Atoi(field)
+			if err != nil {
+				return 0, nil, err
+			}
+			if lit == 0 {
+				clauses = append(clauses, clause)
+				clause = nil
+				continue
+			}
+			clause = append(clause, lit)
+		}
+	}
+	return numVars, clauses, scanner.Err()
+}
+
+// solveCNF is a simple DPLL solver, which returns a model of the formula if it is satisfiable.
+func solveCNF(numVars int, clauses [][]int) ([]int, bool) {
+	assignment := make([]int, numVars+1)
+	if !dpll(assignment, clauses) {
+		return nil, false
+	}
+	model := make([]int, numVars)
+	for v := 1; v <= numVars; v++ {
+		model[v-1] = v * assignment[v]
+	}
+	return model, true
+}
+
+func dpll(assignment []int, clauses [][]int) bool {
+	value := func(lit int) int {
+		if lit > 0 {
+			return assignment[lit]
+		}
+		return -assignment[-lit]
+	}
+
+	var trail []int
+	undo := func() {
+		for _, v := range trail {
+			assignment[v] = 0
+		}
+	}
+
+	// unit propagation
+	for changed := true; changed; {
+		changed = false
+		for _, clause := range clauses {
+			var unassigned, count int
+			satisfied := false
+			for _, lit := range clause {
+				switch value(lit) {
+				case 1:
+					satisfied = true
+				case 0:
+					unassigned = lit
+					count++
+				}
+			}
+			if satisfied {
+				continue
+			}
+			if count == 0 {
+				undo()
+				return false
+			}
+			if count == 1 {
+				v := unassigned
+				if v < 0 {
+					v = -v
+				}
+				assignment[v] = unassigned / v
+				trail = append(trail, v)
+				changed = true
+			}
+		}
+	}
+
+	for v := 1; v < len(assignment); v++ {
+		if assignment[v] != 0 {
+			continue
+		}
+		for _, guess := range []int{-1, 1} {
+			assignment[v] = guess
+			if dpll(assignment, clauses) {
+				return true
+			}
+		}
+		assignment[v] = 0
+		undo()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package solve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// ExternalEngine is an Engine which encodes the level with EncodeCNF, and finds solutions
+// by running a SAT solver as a separate process. The solver is run once per solution, and
+// each solution that is found is blocked before running the solver again.
+//
+// The solver is given the path of a DIMACS file as its last argument, and must print its
+// result in the format read by ParseSolverOutput.
+type ExternalEngine struct {
+	// Command is the solver to run, and Args are the arguments given before the file path.
+	Command string
+	Args    []string
+
+	// OnError is called if the level cannot be encoded or the solver fails, before the
+	// channel of solutions is closed. If it is nil, errors are ignored.
+	OnError func(err error)
+}
+
+// SolveAll implements Engine. Like PropagationEngine, unknown tiles which cannot affect
+// whether the level is solved keep their color, and are not included in solutions.
+func (e ExternalEngine) SolveAll(ctx context.Context, g GridSolver) <-chan gs.Assignment {
+	ch := make(chan gs.Assignment)
+
+	go func() {
+		defer close(ch)
+
+		relevant := relevantTiles(g)
+		g = g.Clone()
+		g.UnknownTiles.RemoveIf(func(coord gs.TileCoord) bool {
+			return !relevant.Has(coord)
+		})
+
+		enc, err := g.EncodeCNF()
+		if err != nil {
+			e.fail(err)
+			return
+		}
+		for {
+			spendNodes(ctx, 1)
+			solution, ok, err := e.Solve(ctx, enc)
+			if err != nil {
+				if ctx.Err() == nil {
+					e.fail(err)
+				}
+				return
+			}
+			if !ok {
+				return
+			}
+			enc.Block(solution)
+
+			solved := g.Grid.Clone()
+			solution.Apply(solved)
+			if !solved.Valid() {
+				e.fail(fmt.Errorf("solver found invalid solution %v", solution))
+				return
+			}
+			if !sendSolution(ctx, ch, solution) {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// Solve runs the solver once on enc. If enc is satisfiable, the decoded solution is returned.
+func (e ExternalEngine) Solve(ctx context.Context, enc *Encoding) (solution gs.Assignment, ok bool, err error) {
+	file, err := os.CreateTemp("", "gridspech-*.cnf")
+	if err != nil {
+		return gs.Assignment{}, false, err
+	}
+	defer os.Remove(file.Name())
+
+	err = enc.WriteDIMACS(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return gs.Assignment{}, false, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Command, append(e.Args, file.Name())...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	// solvers conventionally exit with 10 if the formula is satisfiable, and 20 if it is not
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !(errors.As(err, &exitErr) && (exitErr.ExitCode() == 10 || exitErr.ExitCode() == 20)) {
+		return gs.Assignment{}, false, fmt.Errorf("running %s: %w: %s", e.Command, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	satisfiable, model, err := ParseSolverOutput(&stdout)
+	if err != nil || !satisfiable {
+		return gs.Assignment{}, false, err
+	}
+	solution, err = enc.Decode(model)
+	return solution, err == nil, err
+}
+
+func (e ExternalEngine) fail(err error) {
+	if e.OnError != nil {
+		e.OnError(err)
+	}
+}
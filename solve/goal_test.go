@@ -12,11 +12,11 @@ func testSolveGoalsAbstract(t *testing.T, level string, maxColors int, expectedS
 
 	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
 
-	var expectedSolutions []gs.TileSet
-	var actualSolutions []gs.TileSet
+	var expectedSolutions []gs.Assignment
+	var actualSolutions []gs.Assignment
 
 	for _, solutionString := range expectedSolutionStrings {
-		expectedSolutions = append(expectedSolutions, tileSetFromString(solver.Grid, solutionString))
+		expectedSolutions = append(expectedSolutions, assignmentFromString(solver.Grid, solutionString))
 	}
 
 	ch := solver.SolveGoals()
@@ -24,7 +24,7 @@ func testSolveGoalsAbstract(t *testing.T, level string, maxColors int, expectedS
 		actualSolutions = append(actualSolutions, solution)
 	}
 
-	testUnorderedTilesetSliceEq(t, expectedSolutions, actualSolutions)
+	testUnorderedAssignmentSliceEq(t, expectedSolutions, actualSolutions)
 }
 
 func TestGoals_levelB1(t *testing.T) {
@@ -1,32 +1,31 @@
 package solve
 
 import (
-	"sync"
+	"context"
 
 	gs "github.com/deanveloper/gridspech-go"
 )
 
 // SolveGoals will return a channel of solutions for all the goal tiles in g
-func (g GridSolver) SolveGoals() <-chan gs.TileSet {
-
-	iter := make(chan gs.TileSet, 4)
-
-	go func() {
-		defer close(iter)
-		g.solveGoals(iter)
-	}()
+func (g GridSolver) SolveGoals() <-chan gs.Assignment {
+	return g.SolveGoalsContext(context.Background())
+}
 
-	return iter
+// SolveGoalsContext is like SolveGoals, but stops once ctx is cancelled.
+func (g GridSolver) SolveGoalsContext(ctx context.Context) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IterateGoals(ctx))
 }
 
-func (g GridSolver) solveGoals(ch chan<- gs.TileSet) {
+// IterateGoals is like SolveGoalsContext, but returns an iterator. The paths between each
+// pair of goals are found the first time Next is called. Only pairings of the goals which
+// contain every pair in g.GoalPairs are solved, and colors in g.Forbidden are never used.
+func (g GridSolver) IterateGoals(ctx context.Context) SolutionIterator {
 	goalTiles := g.Grid.TilesWith(func(o gs.Tile) bool {
 		return o.Data.Type == gs.TypeGoal
 	}).Slice()
 
 	if len(goalTiles) == 0 {
-		ch <- gs.NewTileSet()
-		return
+		return sliceIter(gs.Assignment{})
 	}
 
 	goalTileCoords := make([]gs.TileCoord, len(goalTiles))
@@ -34,32 +33,20 @@ func (g GridSolver) solveGoals(ch chan<- gs.TileSet) {
 		goalTileCoords[i] = goalTiles[i].Coord
 	}
 
-	var pairsToSolutionMx sync.Mutex
-	pairsToSolutions := make(map[[2]gs.TileCoord][]gs.TileSet)
-	var wg sync.WaitGroup
-	for i1 := 0; i1 < len(goalTiles)-1; i1++ {
-		for i2 := i1 + 1; i2 < len(goalTiles); i2++ {
-			goalPairCoords := [2]gs.TileCoord{goalTiles[i1].Coord, goalTiles[i2].Coord}
-			wg.Add(1)
-			go func() {
-				for c := 0; c < g.Grid.MaxColors; c++ {
-					for path := range g.PathsIter(goalPairCoords[0], goalPairCoords[1], gs.TileColor(c)) {
-						pairsToSolutionMx.Lock()
-						for decorated := range decorateSetBorder(g, gs.TileColor(c), path) {
-							pairsToSolutions[goalPairCoords] = append(pairsToSolutions[goalPairCoords], decorated)
-						}
-						pairsToSolutionMx.Unlock()
-					}
-				}
-				wg.Done()
-			}()
+	var pairsToSolutions map[[2]gs.TileCoord][]gs.Assignment
+	var allGoalPairings [][][2]gs.TileCoord
+	for _, pairing := range allTilePairingSets(goalTileCoords) {
+		if g.hasPairing(pairing) {
+			allGoalPairings = append(allGoalPairings, pairing)
 		}
 	}
-	wg.Wait()
+	return filterAllowed(ctx, g, concatIter(ctx, len(allGoalPairings), func(i int) SolutionIterator {
+		if pairsToSolutions == nil {
+			pairsToSolutions = g.goalPairSolutions(ctx, goalTileCoords)
+		}
 
-	// now we get solutions for each pairing
-	allGoalPairings := allTilePairingSets(goalTileCoords)
-	for _, pairing := range allGoalPairings {
+		// now we get solutions for each pairing
+		pairing := allGoalPairings[i]
 		pairingSolutions := pairsToSolutions[pairing[0]]
 		for pairIndex := 1; pairIndex < len(pairing); pairIndex++ {
 			pair := pairing[pairIndex]
@@ -73,10 +60,35 @@ func (g GridSolver) solveGoals(ch chan<- gs.TileSet) {
 			result = removeIfInvalid(g, tilesToValidate, result)
 			pairingSolutions = result
 		}
-		for _, solution := range pairingSolutions {
-			ch <- solution
+		return sliceIter(pairingSolutions...)
+	}))
+}
+
+// goalPairSolutions returns the decorated paths between each pair of goal tiles.
+func (g GridSolver) goalPairSolutions(ctx context.Context, goalTileCoords []gs.TileCoord) map[[2]gs.TileCoord][]gs.Assignment {
+	var goalPairs [][2]gs.TileCoord
+	for i1 := 0; i1 < len(goalTileCoords)-1; i1++ {
+		for i2 := i1 + 1; i2 < len(goalTileCoords); i2++ {
+			goalPairs = append(goalPairs, [2]gs.TileCoord{goalTileCoords[i1], goalTileCoords[i2]})
 		}
 	}
+
+	// the paths of each pair in each color are independent, so they are found in parallel
+	numColors := g.Grid.MaxColors
+	solutions := make([][]gs.Assignment, len(goalPairs)*numColors)
+	runParallel(g.workers(), len(solutions), func(i int) {
+		goalPairCoords, color := goalPairs[i/numColors], gs.TileColor(i%numColors)
+		paths := g.IteratePaths(ctx, goalPairCoords[0], goalPairCoords[1], color)
+		decorated := decorateSetIterBorders(ctx, g, color, paths)
+		solutions[i] = CollectSolutions(decorated)
+	})
+
+	pairsToSolutions := make(map[[2]gs.TileCoord][]gs.Assignment)
+	for i, sols := range solutions {
+		goalPairCoords := goalPairs[i/numColors]
+		pairsToSolutions[goalPairCoords] = append(pairsToSolutions[goalPairCoords], sols...)
+	}
+	return pairsToSolutions
 }
 
 func allTilePairingSets(tiles []gs.TileCoord) [][][2]gs.TileCoord {
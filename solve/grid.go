@@ -6,6 +6,36 @@ import gs "github.com/deanveloper/gridspech-go"
 type GridSolver struct {
 	Grid         gs.Grid
 	UnknownTiles gs.TileCoordSet
+
+	// Forbidden are the colors which each tile may not have in a solution. See Forbid.
+	Forbidden map[gs.TileCoord]ColorSet
+
+	// GoalPairs are pairs of goals which must be connected to each other in every solution.
+	// See PairGoals.
+	GoalPairs [][2]gs.TileCoord
+
+	// Engine is used by SolveAllTiles to find solutions. If it is nil, the
+	// solutions are found by solving each of the Constraints in turn.
+	Engine Engine
+
+	// Constraints are the rules which SolveAllTiles finds solutions for when Engine is nil.
+	// If it is nil, DefaultConstraints is used.
+	Constraints []Constraint
+
+	// Merge configures how the solutions of each tile are merged together.
+	Merge MergeOptions
+
+	// Sorted makes SolveAllTiles return solutions in a canonical order (see gs.Assignment.Compare)
+	// which is the same between runs. All solutions are found before the first one is returned.
+	Sorted bool
+
+	// Observer is told about events that happen while solving. If it is nil, events are ignored.
+	Observer Observer
+
+	// Workers is the number of goroutines used to solve independent branches of the search at
+	// once. If it is 0, runtime.GOMAXPROCS(0) is used, and if it is 1, g is solved entirely on
+	// the goroutine which reads its solutions.
+	Workers int
 }
 
 // NewGridSolver creates a GridSolver
@@ -25,5 +55,5 @@ func NewGridSolver(solving gs.Grid) GridSolver {
 func (g GridSolver) Clone() GridSolver {
 	newUnknownTiles := gs.NewTileCoordSet()
 	newUnknownTiles.Merge(g.UnknownTiles)
-	return GridSolver{Grid: g.Grid.Clone(), UnknownTiles: newUnknownTiles}
+	return GridSolver{Grid: g.Grid.Clone(), UnknownTiles: newUnknownTiles, Forbidden: g.Forbidden, GoalPairs: g.GoalPairs, Engine: g.Engine, Constraints: g.Constraints, Merge: g.Merge, Sorted: g.Sorted, Observer: g.Observer, Workers: g.Workers}
 }
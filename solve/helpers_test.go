@@ -8,16 +8,16 @@ import (
 	"github.com/deanveloper/gridspech-go/solve"
 )
 
-func testSolveAbstract(t *testing.T, level string, expectedSolutionsStrings []string, maxColors int, f func(solve.GridSolver) <-chan gs.TileSet) {
+func testSolveAbstract(t *testing.T, level string, expectedSolutionsStrings []string, maxColors int, f func(solve.GridSolver) <-chan gs.Assignment) {
 	t.Helper()
 
 	solver := solve.NewGridSolver(gs.MakeGridFromString(level, maxColors))
 
-	var expectedSolutions []gs.TileSet
-	var actualSolutions []gs.TileSet
+	var expectedSolutions []gs.Assignment
+	var actualSolutions []gs.Assignment
 
 	for _, solutionString := range expectedSolutionsStrings {
-		expectedSolutions = append(expectedSolutions, tileSetFromString(solver.Grid, solutionString))
+		expectedSolutions = append(expectedSolutions, assignmentFromString(solver.Grid, solutionString))
 	}
 
 	solutionsCh := f(solver)
@@ -25,12 +25,12 @@ func testSolveAbstract(t *testing.T, level string, expectedSolutionsStrings []st
 		actualSolutions = append(actualSolutions, solution)
 	}
 
-	testUnorderedTilesetSliceEq(t, expectedSolutions, actualSolutions)
+	testUnorderedAssignmentSliceEq(t, expectedSolutions, actualSolutions)
 }
 
-func tileSetFromString(grid gs.Grid, str string) gs.TileSet {
+func assignmentFromString(grid gs.Grid, str string) gs.Assignment {
 	lines := strings.Split(strings.Trim(str, "{}"), "|")
-	var ts gs.TileSet
+	var a gs.Assignment
 	for i, line := range lines {
 		y := len(lines) - i - 1
 		x := -1
@@ -40,15 +40,13 @@ func tileSetFromString(grid gs.Grid, str string) gs.TileSet {
 				break
 			}
 			x = x + 1 + index
-			tileWithColor := *grid.TileAt(x, y)
-			tileWithColor.Data.Color = gs.TileColor(line[x] - '0')
-			ts.Add(tileWithColor)
+			a.Set(gs.TileCoord{X: x, Y: y}, gs.TileColor(line[x]-'0'))
 		}
 	}
-	return ts
+	return a
 }
 
-func commaSeparatedSlice(slice []gs.TileSet) string {
+func commaSeparatedSlice(slice []gs.Assignment) string {
 	var asStr []string
 	for _, v := range slice {
 		asStr = append(asStr, v.String())
@@ -56,7 +54,7 @@ func commaSeparatedSlice(slice []gs.TileSet) string {
 	return strings.Join(asStr, ",")
 }
 
-func testUnorderedTilesetSliceEq(t *testing.T, expected, actual []gs.TileSet) {
+func testUnorderedAssignmentSliceEq(t *testing.T, expected, actual []gs.Assignment) {
 	t.Helper()
 
 	for i1 := range expected {
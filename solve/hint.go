@@ -0,0 +1,750 @@
+package solve
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// Names of the rules which are used to make deductions.
+const (
+	// RuleDotSaturated means that a dot already touches enough colored tiles,
+	// so its other neighbors must be uncolored.
+	RuleDotSaturated = "dot-saturated"
+
+	// RuleDotStarved means that a dot has exactly as many neighbors which can be
+	// colored as it needs, so they must all be colored.
+	RuleDotStarved = "dot-starved"
+
+	// RuleGoalDegree means that a goal must have exactly one neighbor with its color.
+	RuleGoalDegree = "goal-degree"
+
+	// RulePathDegree means that a tile on a goal's path must have exactly two
+	// neighbors with its color.
+	RulePathDegree = "path-degree"
+
+	// RuleCrownUnreachable means that a tile cannot be connected to any crown of a color,
+	// so it cannot have that color.
+	RuleCrownUnreachable = "crown-unreachable"
+
+	// RuleCrownSeparation means that a tile would connect two crowns of the same color.
+	RuleCrownSeparation = "crown-separation"
+
+	// RuleJoinFull means that a join's blob already contains enough special tiles,
+	// so no other special tiles may join it.
+	RuleJoinFull = "join-full"
+)
+
+// ColorSet is a set of colors, where bit n is set if the set contains color n.
+type ColorSet uint64
+
+// AllColors returns a ColorSet which contains every color less than maxColors.
+func AllColors(maxColors int) ColorSet {
+	if maxColors >= 64 {
+		return ^ColorSet(0)
+	}
+	return ColorSet(1)<<maxColors - 1
+}
+
+// SingleColor returns a ColorSet which only contains c.
+func SingleColor(c gs.TileColor) ColorSet {
+	return ColorSet(1) << c
+}
+
+// Has returns if c is in the set.
+func (cs ColorSet) Has(c gs.TileColor) bool {
+	return cs&SingleColor(c) != 0
+}
+
+// Len returns the number of colors in the set.
+func (cs ColorSet) Len() int {
+	return bits.OnesCount64(uint64(cs))
+}
+
+// Only returns the color in the set if the set contains exactly one color.
+func (cs ColorSet) Only() (gs.TileColor, bool) {
+	if cs.Len() != 1 {
+		return 0, false
+	}
+	return gs.TileColor(bits.TrailingZeros64(uint64(cs))), true
+}
+
+// Colors returns the colors in the set in increasing order.
+func (cs ColorSet) Colors() []gs.TileColor {
+	var colors []gs.TileColor
+	for rest := cs; rest != 0; rest &= rest - 1 {
+		colors = append(colors, gs.TileColor(bits.TrailingZeros64(uint64(rest))))
+	}
+	return colors
+}
+
+func (cs ColorSet) String() string {
+	var strs []string
+	for _, c := range cs.Colors() {
+		strs = append(strs, fmt.Sprint(c))
+	}
+	return "{" + strings.Join(strs, ", ") + "}"
+}
+
+// Deduction is a conclusion about the colors which a tile may have.
+type Deduction struct {
+	// Coord is the tile which the deduction is about.
+	Coord gs.TileCoord
+
+	// Colors are the colors which the tile may still have, and Removed are the
+	// colors which were ruled out by this deduction.
+	Colors, Removed ColorSet
+
+	// Rule is the name of the rule which made the deduction, ie RuleDotStarved.
+	Rule string
+
+	// Reason explains why the deduction is true.
+	Reason string
+
+	// DependsOn are the tiles whose colors the deduction is based on, sorted by X and then Y.
+	DependsOn []gs.TileCoord
+}
+
+func (d Deduction) String() string {
+	var conclusion string
+	if c, ok := d.Colors.Only(); ok {
+		if c == gs.ColorNone {
+			conclusion = "must be uncolored"
+		} else {
+			conclusion = fmt.Sprintf("must have color %d", c)
+		}
+	} else if d.Removed == SingleColor(gs.ColorNone) {
+		conclusion = "must be colored"
+	} else {
+		conclusion = fmt.Sprintf("cannot have %s %v", plural(d.Removed.Len(), "color"), d.Removed)
+	}
+	return fmt.Sprintf("tile %v %s because %s", d.Coord, conclusion, d.Reason)
+}
+
+// ContradictionError is returned when the known tiles of a level break a rule,
+// meaning that the level cannot be solved.
+type ContradictionError struct {
+	// Rule is the name of the rule which is broken.
+	Rule string
+
+	// Coord is the tile whose rule is broken.
+	Coord gs.TileCoord
+
+	// Reason explains the contradiction.
+	Reason string
+
+	// DependsOn are the tiles which cause the contradiction, sorted by X and then Y.
+	DependsOn []gs.TileCoord
+}
+
+func (e *ContradictionError) Error() string {
+	return fmt.Sprintf("contradiction at %v: %s", e.Coord, e.Reason)
+}
+
+// Deductions returns the deductions which can be made about the unknown tiles of g,
+// in the order that they were made. Each deduction may depend on the ones before it.
+//
+// Deductions are only made for the built-in tile types. If the known tiles of g cannot
+// be part of a solution, the deductions made so far are returned along with a
+// *ContradictionError.
+func (g GridSolver) Deductions() ([]Deduction, error) {
+	d, err := newDeducer(g)
+	if err != nil {
+		return nil, err
+	}
+	d.explain = true
+	err = d.run()
+	return d.deductions, err
+}
+
+// Hint returns the first deduction which determines the color of a tile. If no tile's
+// color can be determined, the first deduction is returned instead. ok is false if
+// no deductions can be made.
+func (g GridSolver) Hint() (hint Deduction, ok bool, err error) {
+	deductions, err := g.Deductions()
+	if err != nil {
+		return Deduction{}, false, err
+	}
+	for _, deduction := range deductions {
+		if deduction.Colors.Len() == 1 {
+			return deduction, true, nil
+		}
+	}
+	if len(deductions) > 0 {
+		return deductions[0], true, nil
+	}
+	return Deduction{}, false, nil
+}
+
+// deducer keeps track of the colors which each tile of a grid may have,
+// and narrows them down using the deduction rules.
+type deducer struct {
+	grid    gs.Grid
+	domains [][]ColorSet
+
+	// if explain is true, each deduction is recorded in deductions.
+	explain    bool
+	deductions []Deduction
+}
+
+// deductionRule makes deductions about the tiles around t. It returns if any
+// deductions were made.
+type deductionRule func(d *deducer, t gs.Tile) (bool, error)
+
+// deductionRules are ordered from simplest to most complex, so that
+// hints use the simplest explanation possible.
+var deductionRules = []deductionRule{
+	(*deducer).dotRule,
+	(*deducer).goalRule,
+	(*deducer).pathRule,
+	(*deducer).joinRule,
+	(*deducer).crownSeparationRule,
+	(*deducer).crownUnreachableRule,
+}
+
+func newDeducer(g GridSolver) (*deducer, error) {
+	if g.Grid.MaxColors > 64 {
+		return nil, fmt.Errorf("cannot make deductions with more than 64 colors, got %d", g.Grid.MaxColors)
+	}
+
+	d := &deducer{grid: g.Grid, domains: make([][]ColorSet, g.Grid.Width())}
+	for x, col := range g.Grid.Tiles {
+		d.domains[x] = make([]ColorSet, len(col))
+		for y, tile := range col {
+			switch {
+			case tile.Data.Type == gs.TypeHole:
+			case g.UnknownTiles.Has(tile.Coord):
+				d.domains[x][y] = g.allowedColors(tile.Coord)
+			default:
+				d.domains[x][y] = SingleColor(tile.Data.Color)
+			}
+		}
+	}
+	return d, nil
+}
+
+// run applies the deduction rules until no more deductions can be made. Whenever a rule
+// makes a deduction, the rules are started over from the beginning.
+func (d *deducer) run() error {
+	for {
+		progress, err := d.step()
+		if err != nil || !progress {
+			return err
+		}
+	}
+}
+
+// step applies the first rule which makes a deduction, and returns if one was made.
+func (d *deducer) step() (bool, error) {
+	for _, rule := range deductionRules {
+		for x := 0; x < d.grid.Width(); x++ {
+			for y := 0; y < d.grid.Height(); y++ {
+				tile := *d.grid.TileAt(x, y)
+				if tile.Data.Type == gs.TypeHole {
+					continue
+				}
+				progress, err := rule(d, tile)
+				if err != nil || progress {
+					return progress, err
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// propagate applies every rule to every tile until no more deductions can be made. Unlike run,
+// it does not start over after each deduction, so it is faster but the deductions are not
+// in the simplest order.
+func (d *deducer) propagate() error {
+	for progress := true; progress; {
+		progress = false
+		for _, rule := range deductionRules {
+			for x := 0; x < d.grid.Width(); x++ {
+				for y := 0; y < d.grid.Height(); y++ {
+					tile := *d.grid.TileAt(x, y)
+					if tile.Data.Type == gs.TypeHole {
+						continue
+					}
+					changed, err := rule(d, tile)
+					if err != nil {
+						return err
+					}
+					progress = progress || changed
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of d which can be narrowed down without affecting d.
+func (d *deducer) clone() *deducer {
+	domains := make([][]ColorSet, len(d.domains))
+	for x, col := range d.domains {
+		domains[x] = append([]ColorSet(nil), col...)
+	}
+	return &deducer{grid: d.grid, domains: domains}
+}
+
+func (d *deducer) domain(coord gs.TileCoord) ColorSet {
+	return d.domains[coord.X][coord.Y]
+}
+
+// color returns the color of the tile at coord if it is known.
+func (d *deducer) color(coord gs.TileCoord) (gs.TileColor, bool) {
+	return d.domain(coord).Only()
+}
+
+// restrict narrows down the colors of the tile at coord to only those in allowed. It
+// returns if any colors were removed.
+func (d *deducer) restrict(coord gs.TileCoord, allowed ColorSet, rule, reason string, dependsOn []gs.TileCoord) (bool, error) {
+	old := d.domain(coord)
+	narrowed := old & allowed
+	if narrowed == old {
+		return false, nil
+	}
+	if narrowed == 0 {
+		return false, &ContradictionError{Rule: rule, Coord: coord, Reason: reason, DependsOn: dependsOn}
+	}
+
+	d.domains[coord.X][coord.Y] = narrowed
+	if !d.explain {
+		return true, nil
+	}
+	d.deductions = append(d.deductions, Deduction{
+		Coord:     coord,
+		Colors:    narrowed,
+		Removed:   old &^ narrowed,
+		Rule:      rule,
+		Reason:    reason,
+		DependsOn: dependsOn,
+	})
+	return true, nil
+}
+
+// knownBlob returns the tiles which are definitely in the blob of the tile at coord.
+// The tile at coord must have a known color.
+func (d *deducer) knownBlob(coord gs.TileCoord) gs.TileCoordSet {
+	color, _ := d.color(coord)
+	return d.reachable([]gs.TileCoord{coord}, func(o gs.TileCoord) bool {
+		return d.domain(o) == SingleColor(color)
+	})
+}
+
+// reachable returns all tiles which can be reached from start while only passing through
+// tiles such that pred returns true. Tiles in start are only included if pred returns true.
+func (d *deducer) reachable(start []gs.TileCoord, pred func(o gs.TileCoord) bool) gs.TileCoordSet {
+	seen := gs.NewTileCoordSet()
+	var stack []gs.TileCoord
+	for _, coord := range start {
+		if pred(coord) && !seen.Has(coord) {
+			seen.Add(coord)
+			stack = append(stack, coord)
+		}
+	}
+	for len(stack) > 0 {
+		coord := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, link := range neighborLinks(d.grid, coord) {
+			next := link.tile.Coord
+			if !seen.Has(next) && pred(next) {
+				seen.Add(next)
+				stack = append(stack, next)
+			}
+		}
+	}
+	return seen
+}
+
+// dots must touch exactly n colored tiles.
+func (d *deducer) dotRule(t gs.Tile) (bool, error) {
+	var n int
+	switch t.Data.Type {
+	case gs.TypeDot1:
+		n = 1
+	case gs.TypeDot2:
+		n = 2
+	case gs.TypeDot3:
+		n = 3
+	default:
+		return false, nil
+	}
+
+	links := neighborLinks(d.grid, t.Coord)
+	var colored, canBeColored []neighborLink
+	var undecided []gs.TileCoord
+	dependsOn := []gs.TileCoord{t.Coord}
+	for _, link := range links {
+		domain := d.domain(link.tile.Coord)
+		if !domain.Has(gs.ColorNone) {
+			colored = append(colored, link)
+		}
+		if domain&^SingleColor(gs.ColorNone) != 0 {
+			canBeColored = append(canBeColored, link)
+		}
+		if domain.Has(gs.ColorNone) && domain != SingleColor(gs.ColorNone) {
+			undecided = append(undecided, link.tile.Coord)
+		} else {
+			dependsOn = append(dependsOn, link.tile.Coord)
+		}
+	}
+	dependsOn = sortCoords(dependsOn)
+
+	if len(colored) > n {
+		reason := fmt.Sprintf("%v at %v touches %d colored tiles, but wants %d%s",
+			t.Data.Type, t.Coord, len(colored), n, arrowNote(colored))
+		return false, &ContradictionError{Rule: RuleDotSaturated, Coord: t.Coord, Reason: reason, DependsOn: dependsOn}
+	}
+	if len(canBeColored) < n {
+		reason := fmt.Sprintf("%v at %v has only %d %s which can be colored, but wants %d%s",
+			t.Data.Type, t.Coord, len(canBeColored), plural(len(canBeColored), "neighbor"), n, arrowNote(canBeColored))
+		return false, &ContradictionError{Rule: RuleDotStarved, Coord: t.Coord, Reason: reason, DependsOn: dependsOn}
+	}
+	if len(undecided) == 0 {
+		return false, nil
+	}
+
+	if len(colored) == n {
+		reason := fmt.Sprintf("%v at %v already touches %d colored %s%s",
+			t.Data.Type, t.Coord, n, plural(n, "tile"), arrowNote(colored))
+		return d.restrictAll(undecided, SingleColor(gs.ColorNone), RuleDotSaturated, reason, dependsOn)
+	}
+	if len(canBeColored) == n {
+		reason := fmt.Sprintf("%v at %v has only %d %s which can be colored%s",
+			t.Data.Type, t.Coord, n, plural(n, "neighbor"), arrowNote(canBeColored))
+		return d.restrictAll(undecided, ^SingleColor(gs.ColorNone), RuleDotStarved, reason, dependsOn)
+	}
+	return false, nil
+}
+
+// goals must have exactly one neighbor with the same color.
+func (d *deducer) goalRule(t gs.Tile) (bool, error) {
+	if t.Data.Type != gs.TypeGoal {
+		return false, nil
+	}
+
+	color, ok := d.color(t.Coord)
+	if !ok {
+		// remove any colors that the goal could not share with a neighbor
+		links := neighborLinks(d.grid, t.Coord)
+		var possible ColorSet
+		for _, link := range links {
+			possible |= d.domain(link.tile.Coord)
+		}
+		reason := fmt.Sprintf("the goal at %v needs a neighbor with the same color", t.Coord)
+		return d.restrict(t.Coord, possible, RuleGoalDegree, reason, linkCoords(t.Coord, links))
+	}
+
+	what := fmt.Sprintf("the goal at %v", t.Coord)
+	return d.degreeRule(t.Coord, color, 1, RuleGoalDegree, what, []gs.TileCoord{t.Coord})
+}
+
+// tiles on the path between two goals must have exactly two neighbors with the same color.
+func (d *deducer) pathRule(t gs.Tile) (bool, error) {
+	if t.Data.Type != gs.TypeGoal {
+		return false, nil
+	}
+	color, ok := d.color(t.Coord)
+	if !ok {
+		return false, nil
+	}
+
+	blob := d.knownBlob(t.Coord)
+	for _, coord := range sortCoords(blob.Slice()) {
+		if d.grid.TileAtCoord(coord).Data.Type == gs.TypeGoal {
+			continue
+		}
+		what := fmt.Sprintf("%v is on the path from the goal at %v, so it", coord, t.Coord)
+		progress, err := d.degreeRule(coord, color, 2, RulePathDegree, what, blob.Slice())
+		if err != nil || progress {
+			return progress, err
+		}
+	}
+	return false, nil
+}
+
+// degreeRule makes deductions about the neighbors of the tile at coord, which must have exactly
+// n neighbors with the given color. what describes the tile at coord, and blob is the set
+// of tiles that the requirement depends on.
+func (d *deducer) degreeRule(coord gs.TileCoord, color gs.TileColor, n int, rule, what string, blob []gs.TileCoord) (bool, error) {
+	links := neighborLinks(d.grid, coord)
+	var same, canBeSame []neighborLink
+	var undecided []gs.TileCoord
+	for _, link := range links {
+		domain := d.domain(link.tile.Coord)
+		if domain == SingleColor(color) {
+			same = append(same, link)
+		} else if domain.Has(color) {
+			undecided = append(undecided, link.tile.Coord)
+		}
+		if domain.Has(color) {
+			canBeSame = append(canBeSame, link)
+		}
+	}
+	dependsOn := sortCoords(append(linkCoords(coord, links), blob...))
+
+	if len(same) > n {
+		reason := fmt.Sprintf("%s has %d neighbors with color %d, but wants %d%s", what, len(same), color, n, arrowNote(same))
+		return false, &ContradictionError{Rule: rule, Coord: coord, Reason: reason, DependsOn: dependsOn}
+	}
+	if len(canBeSame) < n {
+		reason := fmt.Sprintf("%s can only have %d %s with color %d, but wants %d%s",
+			what, len(canBeSame), plural(len(canBeSame), "neighbor"), color, n, arrowNote(canBeSame))
+		return false, &ContradictionError{Rule: rule, Coord: coord, Reason: reason, DependsOn: dependsOn}
+	}
+	if len(undecided) == 0 {
+		return false, nil
+	}
+
+	if len(same) == n {
+		reason := fmt.Sprintf("%s already has %d %s with color %d%s", what, n, plural(n, "neighbor"), color, arrowNote(same))
+		return d.restrictAll(undecided, ^SingleColor(color), rule, reason, dependsOn)
+	}
+	if len(canBeSame) == n {
+		reason := fmt.Sprintf("%s needs %d %s with color %d, and only %d can have it%s",
+			what, n, plural(n, "neighbor"), color, n, arrowNote(canBeSame))
+		return d.restrictAll(undecided, SingleColor(color), rule, reason, dependsOn)
+	}
+	return false, nil
+}
+
+// joins must have exactly n other special tiles in their blob.
+func (d *deducer) joinRule(t gs.Tile) (bool, error) {
+	var n int
+	switch t.Data.Type {
+	case gs.TypeJoin1:
+		n = 1
+	case gs.TypeJoin2:
+		n = 2
+	default:
+		return false, nil
+	}
+	color, ok := d.color(t.Coord)
+	if !ok {
+		return false, nil
+	}
+
+	blob := d.knownBlob(t.Coord)
+	var special int
+	for _, coord := range blob.Slice() {
+		if d.grid.TileAtCoord(coord).Data.Type != gs.TypeBlank {
+			special++
+		}
+	}
+	dependsOn := sortCoords(blob.Slice())
+	if special > n+1 {
+		reason := fmt.Sprintf("%v at %v is connected to %d other special tiles, but wants %d", t.Data.Type, t.Coord, special-1, n)
+		return false, &ContradictionError{Rule: RuleJoinFull, Coord: t.Coord, Reason: reason, DependsOn: dependsOn}
+	}
+	if special < n+1 {
+		return false, nil
+	}
+
+	// any special tile which touches the blob would become part of it
+	var touching []gs.TileCoord
+	var links []neighborLink
+	for _, coord := range dependsOn {
+		for _, link := range neighborLinks(d.grid, coord) {
+			next := link.tile.Coord
+			if link.tile.Data.Type != gs.TypeBlank && !blob.Has(next) && d.domain(next).Has(color) {
+				touching = append(touching, next)
+				links = append(links, link)
+			}
+		}
+	}
+	if len(touching) == 0 {
+		return false, nil
+	}
+	reason := fmt.Sprintf("%v at %v is already connected to %d other special %s%s",
+		t.Data.Type, t.Coord, n, plural(n, "tile"), arrowNote(links))
+	return d.restrictAll(touching, ^SingleColor(color), RuleJoinFull, reason, dependsOn)
+}
+
+// crowns with the same color may not be in the same blob.
+func (d *deducer) crownSeparationRule(t gs.Tile) (bool, error) {
+	if t.Data.Type != gs.TypeCrown {
+		return false, nil
+	}
+	color, ok := d.color(t.Coord)
+	if !ok {
+		return false, nil
+	}
+
+	blob := d.knownBlob(t.Coord)
+	for _, coord := range sortCoords(blob.Slice()) {
+		if coord != t.Coord && d.grid.TileAtCoord(coord).Data.Type == gs.TypeCrown {
+			reason := fmt.Sprintf("the crowns at %v and %v have the same color and are connected", t.Coord, coord)
+			return false, &ContradictionError{Rule: RuleCrownSeparation, Coord: t.Coord, Reason: reason, DependsOn: sortCoords(blob.Slice())}
+		}
+	}
+
+	// if a tile touches this crown's blob, and another crown's blob touches it,
+	// then it cannot have the crowns' color.
+	var touching gs.TileCoordSet
+	for _, coord := range blob.Slice() {
+		for _, link := range neighborLinks(d.grid, coord) {
+			next := link.tile.Coord
+			if !blob.Has(next) && d.domain(next).Has(color) && d.domain(next) != SingleColor(color) {
+				touching.Add(next)
+			}
+		}
+	}
+	for _, coord := range sortCoords(touching.Slice()) {
+		for _, link := range neighborLinks(d.grid, coord) {
+			other := link.tile
+			if blob.Has(other.Coord) || d.domain(other.Coord) != SingleColor(color) {
+				continue
+			}
+			otherBlob := d.knownBlob(other.Coord)
+			for _, otherCrown := range sortCoords(otherBlob.Slice()) {
+				if otherCrown == t.Coord || d.grid.TileAtCoord(otherCrown).Data.Type != gs.TypeCrown {
+					continue
+				}
+				reason := fmt.Sprintf("it would connect the crowns at %v and %v, which both have color %d%s",
+					t.Coord, otherCrown, color, arrowNote([]neighborLink{link}))
+				dependsOn := sortCoords(append(blob.Slice(), otherBlob.Slice()...))
+				return d.restrict(coord, ^SingleColor(color), RuleCrownSeparation, reason, dependsOn)
+			}
+		}
+	}
+	return false, nil
+}
+
+// all tiles with the same color as a crown must be in the blob of a crown with that color.
+func (d *deducer) crownUnreachableRule(t gs.Tile) (bool, error) {
+	if t.Data.Type != gs.TypeCrown {
+		return false, nil
+	}
+	color, ok := d.color(t.Coord)
+	if !ok {
+		return false, nil
+	}
+
+	var crowns []gs.TileCoord
+	for _, col := range d.grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type == gs.TypeCrown && d.domain(tile.Coord).Has(color) {
+				crowns = append(crowns, tile.Coord)
+			}
+		}
+	}
+	reachable := d.reachable(crowns, func(o gs.TileCoord) bool {
+		return d.domain(o).Has(color)
+	})
+
+	var unreachable []gs.TileCoord
+	for _, col := range d.grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type != gs.TypeHole && !reachable.Has(tile.Coord) && d.domain(tile.Coord).Has(color) {
+				unreachable = append(unreachable, tile.Coord)
+			}
+		}
+	}
+	if len(unreachable) == 0 {
+		return false, nil
+	}
+	reason := fmt.Sprintf("the crown at %v has color %d, and no crown with color %d can be connected to it", t.Coord, color, color)
+	return d.restrictAll(unreachable, ^SingleColor(color), RuleCrownUnreachable, reason, sortCoords(crowns))
+}
+
+// restrictAll calls restrict on each coordinate.
+func (d *deducer) restrictAll(coords []gs.TileCoord, allowed ColorSet, rule, reason string, dependsOn []gs.TileCoord) (bool, error) {
+	var progress bool
+	for _, coord := range coords {
+		changed, err := d.restrict(coord, allowed, rule, reason, dependsOn)
+		if err != nil {
+			return progress, err
+		}
+		progress = progress || changed
+	}
+	return progress, nil
+}
+
+// neighborLink is a neighbor of a tile, and if the neighbor is only reachable through an arrow.
+type neighborLink struct {
+	tile  gs.Tile
+	arrow bool
+}
+
+// neighborLinks returns the same tiles as NeighborSlice, but also records which neighbors
+// are reached by wrapping around the grid with an arrow.
+func neighborLinks(g gs.Grid, coord gs.TileCoord) []neighborLink {
+	t := *g.TileAtCoord(coord)
+	directions := []struct {
+		neighbor gs.Tile
+		arrow    bool
+		dx, dy   int
+	}{
+		{g.NorthOf(t), t.Data.ArrowNorth, 0, 1},
+		{g.EastOf(t), t.Data.ArrowEast, 1, 0},
+		{g.SouthOf(t), t.Data.ArrowSouth, 0, -1},
+		{g.WestOf(t), t.Data.ArrowWest, -1, 0},
+	}
+
+	var links []neighborLink
+	for _, dir := range directions {
+		if dir.neighbor.Data.Type == gs.TypeHole {
+			continue
+		}
+		adjacent := gs.TileCoord{X: coord.X + dir.dx, Y: coord.Y + dir.dy}
+		links = append(links, neighborLink{
+			tile:  dir.neighbor,
+			arrow: dir.arrow && dir.neighbor.Coord != adjacent,
+		})
+	}
+	return links
+}
+
+func linkCoords(coord gs.TileCoord, links []neighborLink) []gs.TileCoord {
+	coords := []gs.TileCoord{coord}
+	for _, link := range links {
+		coords = append(coords, link.tile.Coord)
+	}
+	return sortCoords(coords)
+}
+
+// arrowNote describes which of links are through an arrow, if any.
+func arrowNote(links []neighborLink) string {
+	var throughArrow []string
+	for _, link := range links {
+		if link.arrow {
+			throughArrow = append(throughArrow, link.tile.Coord.String())
+		}
+	}
+	switch len(throughArrow) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf(" (%s is a neighbor through an arrow)", throughArrow[0])
+	default:
+		return fmt.Sprintf(" (%s are neighbors through arrows)", strings.Join(throughArrow, ", "))
+	}
+}
+
+// sortCoords sorts coords by X and then Y, and removes duplicates.
+func sortCoords(coords []gs.TileCoord) []gs.TileCoord {
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].X != coords[j].X {
+			return coords[i].X < coords[j].X
+		}
+		return coords[i].Y < coords[j].Y
+	})
+	unique := coords[:0]
+	for i, coord := range coords {
+		if i == 0 || coord != coords[i-1] {
+			unique = append(unique, coord)
+		}
+	}
+	return unique
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,136 @@
+package solve_test
+
+import (
+	"errors"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/example"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestHint(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Level    string
+		Expected string
+	}{
+		{
+			"dot starved",
+			`
+			0  0m3  0
+			_  0    _
+			`,
+			"tile (2, 1) must have color 1 because Dot3 at (1, 1) has only 3 neighbors which can be colored",
+		},
+		{
+			"dot saturated",
+			`1/  0m1  0`,
+			"tile (2, 0) must be uncolored because Dot1 at (1, 0) already touches 1 colored tile",
+		},
+		{
+			"dot through arrow",
+			`0m1<  0  1/`,
+			"tile (1, 0) must be uncolored because Dot1 at (0, 0) already touches 1 colored tile ((2, 0) is a neighbor through an arrow)",
+		},
+		{
+			"goal",
+			example.LevelA1,
+			"tile (1, 0) must have color 1 because the goal at (0, 0) needs 1 neighbor with color 1, and only 1 can have it",
+		},
+		{
+			"crown separation",
+			`1/k  0  1/k`,
+			"tile (1, 0) must be uncolored because it would connect the crowns at (0, 0) and (2, 0), which both have color 1",
+		},
+		{
+			"join full",
+			`1/j1  1/j1  0j1`,
+			"tile (2, 0) must be uncolored because Join1 at (0, 0) is already connected to 1 other special tile",
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			solver := solve.NewGridSolver(gs.MakeGridFromString(testCase.Level, 2))
+			hint, ok, err := solver.Hint()
+			if err != nil || !ok {
+				t.Fatalf("expected a hint, got ok=%v, err=%v", ok, err)
+			}
+			if hint.String() != testCase.Expected {
+				t.Errorf("expected hint %q, got %q", testCase.Expected, hint.String())
+			}
+		})
+	}
+}
+
+func TestDeductions_solvesLevel(t *testing.T) {
+	solver := solve.NewGridSolver(gs.MakeGridFromString(example.LevelA1, 2))
+	deductions, err := solver.Deductions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rules []string
+	for _, deduction := range deductions {
+		rules = append(rules, deduction.Rule)
+		if deduction.Colors != solve.SingleColor(1) {
+			t.Errorf("expected %v to have color 1, got %v", deduction.Coord, deduction.Colors)
+		}
+	}
+	expectedRules := []string{solve.RuleGoalDegree, solve.RulePathDegree, solve.RuleGoalDegree}
+	if len(rules) != len(expectedRules) {
+		t.Fatalf("expected rules %v, got %v", expectedRules, rules)
+	}
+	for i := range rules {
+		if rules[i] != expectedRules[i] {
+			t.Errorf("expected rules %v, got %v", expectedRules, rules)
+			break
+		}
+	}
+}
+
+func TestDeductions_contradiction(t *testing.T) {
+	solver := solve.NewGridSolver(gs.MakeGridFromString(`1/  0/m1  1/`, 2))
+	_, err := solver.Deductions()
+
+	var contradiction *solve.ContradictionError
+	if !errors.As(err, &contradiction) {
+		t.Fatalf("expected a contradiction, got %v", err)
+	}
+	if contradiction.Rule != solve.RuleDotSaturated || contradiction.Coord != (gs.TileCoord{X: 1, Y: 0}) {
+		t.Errorf("unexpected contradiction %+v", contradiction)
+	}
+}
+
+// every deduction must agree with every solution of the level.
+func TestDeductions_agreeWithSolutions(t *testing.T) {
+	levels := []string{
+		example.LevelA2, example.LevelA3, example.LevelA4, example.LevelA5, `
+		0    0    0    0    0    0
+		0    0e   0k   0    0    0
+		0    0    0k   0    0    0
+		0    0    0k   0    0    0
+		0    0    0k   0e   0    0
+		0j1  0e   0    0    0j1  0e
+		`,
+	}
+
+	for _, level := range levels {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+		deductions, err := solver.Deductions()
+		if err != nil {
+			t.Fatalf("unexpected error for level %s: %v", level, err)
+		}
+		_, solutions := solver.CountSolutions(0)
+		for _, solution := range solutions {
+			solved := solver.Grid.Clone()
+			solution.Apply(solved)
+			for _, deduction := range deductions {
+				if color := solved.TileAtCoord(deduction.Coord).Data.Color; !deduction.Colors.Has(color) {
+					t.Errorf("deduction %q contradicts solution\n%v", deduction, solved)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package solve
+
+import (
+	"context"
+	"fmt"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// UnintendedSolution is a solution to a level which is different from its intended solution.
+type UnintendedSolution struct {
+	// Solution is the solution as returned by SolveAllTiles.
+	Solution gs.Assignment
+	// Grid is the level after Solution has been applied.
+	Grid gs.Grid
+	// Diff contains the tiles whose colors differ from the intended solution.
+	Diff gs.TileCoordSet
+}
+
+// UnintendedSolutions returns a channel of all solutions to g which result in a different
+// coloring than intended. intended must be g's level, colored so that it is valid.
+// The returned channel is closed once all solutions have been found, or once ctx is cancelled.
+func (g GridSolver) UnintendedSolutions(ctx context.Context, intended gs.Grid) (<-chan UnintendedSolution, error) {
+	if intended.Width() != g.Grid.Width() || intended.Height() != g.Grid.Height() {
+		return nil, fmt.Errorf("intended solution is %dx%d, but the level is %dx%d",
+			intended.Width(), intended.Height(), g.Grid.Width(), g.Grid.Height())
+	}
+	for x := 0; x < g.Grid.Width(); x++ {
+		for y := 0; y < g.Grid.Height(); y++ {
+			levelTile, intendedTile := *g.Grid.TileAt(x, y), *intended.TileAt(x, y)
+			levelTile.Data.Color, intendedTile.Data.Color = 0, 0
+			if levelTile != intendedTile {
+				return nil, fmt.Errorf("intended solution has %v at %v, but the level has %v",
+					intended.TileAt(x, y).Data, levelTile.Coord, g.Grid.TileAt(x, y).Data)
+			}
+			if !g.UnknownTiles.Has(levelTile.Coord) && g.Grid.TileAt(x, y).Data.Color != intended.TileAt(x, y).Data.Color {
+				return nil, fmt.Errorf("intended solution changes the color of %v, which is not solvable", levelTile.Coord)
+			}
+		}
+	}
+	if violations := intended.Violations(); len(violations) > 0 {
+		return nil, fmt.Errorf("intended solution is not valid: tile %v: %v", violations[0].Tile.Coord, violations[0])
+	}
+
+	iter := make(chan UnintendedSolution)
+	go func() {
+		defer close(iter)
+
+		seen := map[string]struct{}{
+			coloringKey(intended, gs.Assignment{}): {},
+		}
+		for solution := range g.SolveAllTilesContext(ctx) {
+			key := coloringKey(g.Grid, solution)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			solved := g.Grid.Clone()
+			solution.Apply(solved)
+			diff := solved.TilesWith(func(o gs.Tile) bool {
+				return o.Data.Color != intended.TileAtCoord(o.Coord).Data.Color
+			}).ToTileCoordSet()
+
+			select {
+			case iter <- UnintendedSolution{Solution: solution, Grid: solved, Diff: diff}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return iter, nil
+}
@@ -0,0 +1,58 @@
+package solve_test
+
+import (
+	"context"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestUnintendedSolutions(t *testing.T) {
+	const level = `
+	0j1  0  0  0j1
+	`
+	const intended = `
+	1j1  1  1  1j1
+	`
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 3))
+
+	ch, err := solver.UnintendedSolutions(context.Background(), gs.MakeGridFromString(intended, 3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var actual []gs.Assignment
+	for unintended := range ch {
+		if unintended.Diff.Len() != 4 {
+			t.Errorf("expected all 4 tiles to differ, got %v", unintended.Diff)
+		}
+		if !unintended.Grid.Valid() {
+			t.Errorf("expected a valid grid, got\n%v", unintended.Grid)
+		}
+		actual = append(actual, unintended.Solution)
+	}
+
+	expected := []gs.Assignment{
+		assignmentFromString(solver.Grid, "0000"),
+		assignmentFromString(solver.Grid, "2222"),
+	}
+	testUnorderedAssignmentSliceEq(t, expected, actual)
+}
+
+func TestUnintendedSolutions_invalidIntended(t *testing.T) {
+	const level = `
+	0j1  0  0  0j1
+	`
+	cases := map[string]string{
+		"not solved":      `1j1  1  0  1j1`,
+		"wrong size":      `1j1  1  1j1`,
+		"different level": `1j1  1  1  1`,
+	}
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	for name, intended := range cases {
+		if _, err := solver.UnintendedSolutions(context.Background(), gs.MakeGridFromString(intended, 2)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package solve
+
+import (
+	"context"
+	"sort"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// SolutionIterator is a pull-based source of solutions. Solutions are only computed
+// when Next is called, so a consumer which stops early does not pay for solutions it
+// never asks for.
+type SolutionIterator interface {
+	// Next returns the next solution. Once there are no more solutions, or the
+	// iterator's context has been cancelled, it returns false.
+	Next() (gs.Assignment, bool)
+
+	// Close releases any resources held by the iterator. Next should not be called
+	// after the iterator has been closed.
+	Close()
+}
+
+// IterToChan returns a channel which is sent each solution from it, and closed (along with it)
+// once it runs out of solutions or ctx is cancelled.
+func IterToChan(ctx context.Context, it SolutionIterator) <-chan gs.Assignment {
+	ch := make(chan gs.Assignment)
+
+	go func() {
+		defer close(ch)
+		defer it.Close()
+		for {
+			solution, ok := it.Next()
+			if !ok || !sendSolution(ctx, ch, solution) {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// ChanToIter returns an iterator over the solutions sent to ch. Whatever is sending to ch
+// should stop once its own context is cancelled, since closing the iterator cannot stop it.
+func ChanToIter(ch <-chan gs.Assignment) SolutionIterator {
+	return &chanIterator{ch: ch}
+}
+
+type chanIterator struct {
+	ch     <-chan gs.Assignment
+	cancel context.CancelFunc
+}
+
+func (it *chanIterator) Next() (gs.Assignment, bool) {
+	solution, ok := <-it.ch
+	return solution, ok
+}
+
+func (it *chanIterator) Close() {
+	if it.cancel != nil {
+		it.cancel()
+	}
+}
+
+// chanFuncToIter calls f with a context which is cancelled once the returned iterator is closed,
+// so that the goroutines sending to the returned channel stop along with the iterator.
+func chanFuncToIter(ctx context.Context, f func(ctx context.Context) <-chan gs.Assignment) SolutionIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	return &chanIterator{ch: f(ctx), cancel: cancel}
+}
+
+// CollectSolutions reads every solution from it into a slice, then closes it.
+func CollectSolutions(it SolutionIterator) []gs.Assignment {
+	defer it.Close()
+	var solutions []gs.Assignment
+	for {
+		solution, ok := it.Next()
+		if !ok {
+			return solutions
+		}
+		solutions = append(solutions, solution)
+	}
+}
+
+// SortSolutions returns an iterator over the solutions from it, in the order defined by
+// gs.Assignment.Compare. All of the solutions from it are collected the first time Next is called.
+func SortSolutions(it SolutionIterator) SolutionIterator {
+	return &sortedIter{in: it, key: func(solution gs.Assignment) gs.Assignment { return solution }}
+}
+
+// sortedIter iterates over the solutions from in, ordered by comparing their keys.
+type sortedIter struct {
+	in        SolutionIterator
+	key       func(gs.Assignment) gs.Assignment
+	solutions []gs.Assignment
+	collected bool
+}
+
+func (it *sortedIter) Next() (gs.Assignment, bool) {
+	if !it.collected {
+		it.collected = true
+		type keyed struct{ solution, key gs.Assignment }
+		var all []keyed
+		for _, solution := range CollectSolutions(it.in) {
+			all = append(all, keyed{solution, it.key(solution)})
+		}
+		sort.SliceStable(all, func(i, j int) bool {
+			if cmp := all[i].key.Compare(all[j].key); cmp != 0 {
+				return cmp < 0
+			}
+			return all[i].solution.Compare(all[j].solution) < 0
+		})
+		for _, k := range all {
+			it.solutions = append(it.solutions, k.solution)
+		}
+	}
+	if len(it.solutions) == 0 {
+		return gs.Assignment{}, false
+	}
+	solution := it.solutions[0]
+	it.solutions = it.solutions[1:]
+	return solution, true
+}
+
+func (it *sortedIter) Close() {
+	if !it.collected {
+		it.collected = true
+		it.in.Close()
+	}
+	it.solutions = nil
+}
+
+// iterFunc is a SolutionIterator which calls next for each solution.
+type iterFunc func() (gs.Assignment, bool)
+
+func (f iterFunc) Next() (gs.Assignment, bool) {
+	return f()
+}
+
+func (f iterFunc) Close() {
+}
+
+// sliceIter returns an iterator over solutions.
+func sliceIter(solutions ...gs.Assignment) SolutionIterator {
+	return iterFunc(func() (gs.Assignment, bool) {
+		if len(solutions) == 0 {
+			return gs.Assignment{}, false
+		}
+		solution := solutions[0]
+		solutions = solutions[1:]
+		return solution, true
+	})
+}
+
+// emptyIter returns an iterator without any solutions.
+func emptyIter() SolutionIterator {
+	return sliceIter()
+}
+
+// flatMapIter calls f for each solution from in, and iterates over each of
+// the returned iterators in turn.
+type flatMapIter struct {
+	ctx context.Context
+	in  SolutionIterator
+	f   func(gs.Assignment) SolutionIterator
+	cur SolutionIterator
+}
+
+func flatMap(ctx context.Context, in SolutionIterator, f func(gs.Assignment) SolutionIterator) SolutionIterator {
+	return &flatMapIter{ctx: ctx, in: in, f: f}
+}
+
+func (it *flatMapIter) Next() (gs.Assignment, bool) {
+	for it.ctx.Err() == nil {
+		if it.cur != nil {
+			if solution, ok := it.cur.Next(); ok {
+				return solution, true
+			}
+			it.cur.Close()
+			it.cur = nil
+		}
+
+		solution, ok := it.in.Next()
+		if !ok {
+			return gs.Assignment{}, false
+		}
+		it.cur = it.f(solution)
+	}
+	return gs.Assignment{}, false
+}
+
+func (it *flatMapIter) Close() {
+	if it.cur != nil {
+		it.cur.Close()
+		it.cur = nil
+	}
+	it.in.Close()
+}
+
+// concatIter iterates over f(0), f(1), ..., f(n-1) in turn. Each iterator is only
+// created once the previous one has run out of solutions.
+func concatIter(ctx context.Context, n int, f func(i int) SolutionIterator) SolutionIterator {
+	i := 0
+	indices := iterFunc(func() (gs.Assignment, bool) {
+		if i >= n {
+			return gs.Assignment{}, false
+		}
+		i++
+		return gs.Assignment{}, true
+	})
+	return flatMap(ctx, indices, func(gs.Assignment) SolutionIterator {
+		return f(i - 1)
+	})
+}
+
+// mapIter replaces each solution from in with the result of f, skipping it if f returns false.
+type mapIter struct {
+	ctx context.Context
+	in  SolutionIterator
+	f   func(gs.Assignment) (gs.Assignment, bool)
+}
+
+func (it *mapIter) Next() (gs.Assignment, bool) {
+	for it.ctx.Err() == nil {
+		solution, ok := it.in.Next()
+		if !ok {
+			return gs.Assignment{}, false
+		}
+		if mapped, keep := it.f(solution); keep {
+			return mapped, true
+		}
+	}
+	return gs.Assignment{}, false
+}
+
+func (it *mapIter) Close() {
+	it.in.Close()
+}
+
+// filterIter only iterates over the solutions from in for which keep returns true.
+func filterIter(ctx context.Context, in SolutionIterator, keep func(gs.Assignment) bool) SolutionIterator {
+	return &mapIter{ctx: ctx, in: in, f: func(solution gs.Assignment) (gs.Assignment, bool) {
+		return solution, keep(solution)
+	}}
+}
+
+// permutationIter iterates over the permutations with repetition of n items in r slots. The
+// first slot changes the fastest.
+type permutationIter struct {
+	n    int
+	perm []int
+	done bool
+}
+
+func newPermutationIter(n, r int) *permutationIter {
+	it := &permutationIter{n: n, done: n == 0 && r > 0}
+	if r > 0 {
+		it.perm = make([]int, r)
+	}
+	return it
+}
+
+func (it *permutationIter) next() ([]int, bool) {
+	if it.done {
+		return nil, false
+	}
+	result := it.perm
+
+	// advance to the next permutation, which will be returned on the next call
+	it.perm = append([]int(nil), it.perm...)
+	it.done = true
+	for i := range it.perm {
+		it.perm[i]++
+		if it.perm[i] < it.n {
+			it.done = false
+			break
+		}
+		it.perm[i] = 0
+	}
+	return result, true
+}
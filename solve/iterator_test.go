@@ -0,0 +1,147 @@
+package solve_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+const levelF10 = `
+	0    0    0    0    0    0
+	0    0e   0k   0    0    0
+	0    0    0k   0    0    0
+	0    0    0k   0    0    0
+	0    0    0k   0e   0    0
+	0j1  0e   0    0    0j1  0e
+	`
+
+func TestIterateAllTiles_levelF10(t *testing.T) {
+	solutions := []string{
+		"111111|100001|111100|100010|101010|101110",
+		"000000|011110|000011|011101|010101|010001",
+	}
+
+	testSolveAbstract(t, levelF10, solutions, 2, func(g solve.GridSolver) <-chan gs.Assignment {
+		return solve.IterToChan(context.Background(), g.IterateAllTiles(context.Background()))
+	})
+}
+
+func TestIterateAllTiles_earlyExit(t *testing.T) {
+	const level = `
+	0  0  0  0
+	0  0  0  0
+	0  0  0  0
+	0  0  0  0
+	`
+	before := runtime.NumGoroutine()
+
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 3))
+	solver.Workers = 1
+	it := solver.IterateAllTiles(context.Background())
+	if _, ok := it.Next(); !ok {
+		t.Fatal("expected a solution")
+	}
+	it.Close()
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected no goroutines to be started, went from %d to %d", before, after)
+	}
+}
+
+func TestIterateAllTiles_earlyExitParallel(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	solver := solve.NewGridSolver(gs.MakeGridFromString(levelF10, 2))
+	solver.Workers = 4
+	it := solver.IterateAllTiles(context.Background())
+	if _, ok := it.Next(); !ok {
+		t.Fatal("expected a solution")
+	}
+	it.Close()
+
+	// goroutines which have finished may take a moment to stop being counted
+	after := runtime.NumGoroutine()
+	for i := 0; i < 100 && after > before; i++ {
+		time.Sleep(time.Millisecond)
+		after = runtime.NumGoroutine()
+	}
+	if after > before {
+		t.Errorf("expected all goroutines to stop, went from %d to %d", before, after)
+	}
+}
+
+func TestIterateShapes_prune(t *testing.T) {
+	const level = `
+	0  0  0
+	0  0  0
+	`
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+
+	// pruning every shape leaves only the starting tile
+	shapes := solver.IterateShapes(context.Background(), gs.TileCoord{X: 1, Y: 0}, 1)
+	var count int
+	for {
+		_, ok := shapes.Next()
+		if !ok {
+			break
+		}
+		shapes.Prune()
+		count++
+	}
+	if count != 1 {
+		t.Errorf("expected 1 shape, got %d", count)
+	}
+}
+
+func TestChanToIter(t *testing.T) {
+	const level = `
+	0  0   0
+	0  0m2 0
+	`
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	expected := len(solve.CollectSolutions(solver.IterateDots(context.Background())))
+	actual := len(solve.CollectSolutions(solve.ChanToIter(solver.SolveDots())))
+	if expected == 0 || expected != actual {
+		t.Errorf("expected %d solutions, got %d", expected, actual)
+	}
+}
+
+func TestPermutation(t *testing.T) {
+	var perms []string
+	for perm := range solve.Permutation(2, 2) {
+		perms = append(perms, fmt.Sprint(perm))
+	}
+	const expected = "[[0 0] [1 0] [0 1] [1 1]]"
+	if fmt.Sprint(perms) != expected {
+		t.Errorf("expected %v, got %v", expected, perms)
+	}
+}
+
+func TestIterateAllTiles_parallel(t *testing.T) {
+	levels := []string{levelF10, `
+	0e  0    0  0e
+	0   0m2  0  0
+	0   0    0  0
+	`, `
+	0e  0   0e  0
+	0   0   0   0
+	0e  0   0   0e
+	0   0k  0   0j1
+	`}
+
+	for _, level := range levels {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+		solver.Workers = 1
+		serial := solve.CollectSolutions(solver.IterateAllTiles(context.Background()))
+
+		solver.Workers = 4
+		parallel := solve.CollectSolutions(solver.IterateAllTiles(context.Background()))
+
+		testUnorderedAssignmentSliceEq(t, serial, parallel)
+	}
+}
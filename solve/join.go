@@ -1,85 +1,94 @@
 package solve
 
 import (
+	"context"
+
 	gs "github.com/deanveloper/gridspech-go"
 )
 
 // SolveJoins returns a channel of solutions for all of the Join tiles.
-func (g GridSolver) SolveJoins() <-chan gs.TileSet {
+func (g GridSolver) SolveJoins() <-chan gs.Assignment {
+	return g.SolveJoinsContext(context.Background())
+}
+
+// SolveJoinsContext is like SolveJoins, but stops once ctx is cancelled.
+func (g GridSolver) SolveJoinsContext(ctx context.Context) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IterateJoins(ctx))
+}
+
+// IterateJoins is like SolveJoinsContext, but returns an iterator.
+func (g GridSolver) IterateJoins(ctx context.Context) SolutionIterator {
 	joinTiles := g.Grid.TilesWith(func(o gs.Tile) bool {
 		return o.Data.Type == gs.TypeJoin1 || o.Data.Type == gs.TypeJoin2
 	}).Slice()
 
 	if len(joinTiles) == 0 {
-		ch := make(chan gs.TileSet, 1)
-		ch <- gs.NewTileSet()
-		close(ch)
-		return ch
+		return sliceIter(gs.Assignment{})
 	}
 
-	tilesToSolutions := make([]<-chan gs.TileSet, len(joinTiles))
+	tilesToSolutions := make([]SolutionIterator, len(joinTiles))
 	for i, tile := range joinTiles {
-		tilesToSolutions[i] = g.SolveJoin(tile)
+		tilesToSolutions[i] = g.IterateJoin(ctx, tile)
 	}
 
 	// now merge them all together
 	for i := 1; i < len(joinTiles); i++ {
-		mergedIter := MergeSolutionsIters(tilesToSolutions[i-1], tilesToSolutions[i])
-		tilesToSolutions[i] = mergedIter
+		tilesToSolutions[i] = g.merge(ctx, tilesToSolutions[i-1], tilesToSolutions[i])
 	}
 
 	return tilesToSolutions[len(tilesToSolutions)-1]
 }
 
 // SolveJoin returns a channel of solutions for an individual join tile.
-func (g GridSolver) SolveJoin(join gs.Tile) <-chan gs.TileSet {
-	joinIter := make(chan gs.TileSet)
-
-	go func() {
-		defer close(joinIter)
-
-		var joinNum int
-		switch join.Data.Type {
-		case gs.TypeJoin1:
-			joinNum = 1
-		case gs.TypeJoin2:
-			joinNum = 2
-		default:
-			panic("not a join tile")
-		}
+func (g GridSolver) SolveJoin(join gs.Tile) <-chan gs.Assignment {
+	return g.SolveJoinContext(context.Background(), join)
+}
 
-		for c := 0; c < g.Grid.MaxColors; c++ {
-			color := gs.TileColor(c)
-			shapeCh, pruneCh := g.ShapesIter(join.Coord, color)
-			for shape := range shapeCh {
-				prune := shouldPruneJoin(g, shape, color, joinNum)
-				pruneCh <- prune
-
-				specialTiles := numSpecialTiles(g, shape, joinNum)
-				if !prune && specialTiles == joinNum+1 {
-					for decorated := range decorateSetBorder(g, color, shape) {
-						joinIter <- decorated
-					}
-				}
-			}
-		}
-	}()
+// SolveJoinContext is like SolveJoin, but stops once ctx is cancelled.
+func (g GridSolver) SolveJoinContext(ctx context.Context, join gs.Tile) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IterateJoin(ctx, join))
+}
+
+// IterateJoin is like SolveJoinContext, but returns an iterator.
+func (g GridSolver) IterateJoin(ctx context.Context, join gs.Tile) SolutionIterator {
+	var joinNum int
+	switch join.Data.Type {
+	case gs.TypeJoin1:
+		joinNum = 1
+	case gs.TypeJoin2:
+		joinNum = 2
+	default:
+		panic("not a join tile")
+	}
 
-	return joinIter
+	return filterAllowed(ctx, g, concatIter(ctx, g.Grid.MaxColors, func(c int) SolutionIterator {
+		color := gs.TileColor(c)
+		shapes := g.IterateShapes(ctx, join.Coord, color)
+		return flatMap(ctx, shapes, func(shape gs.Assignment) SolutionIterator {
+			if shouldPruneJoin(g, shape, color, joinNum) {
+				shapes.Prune()
+				return emptyIter()
+			}
+			if numSpecialTiles(g, shape, joinNum) != joinNum+1 {
+				return emptyIter()
+			}
+			return decorateSetBorder(ctx, g, color, shape)
+		})
+	}))
 }
 
 // trim if:
 // - too many special tiles in the shape
 // - if the shape contains a goal tile, it must be a path
 // - if joinNum is 1, it cannot contain a goal tile
-func shouldPruneJoin(g GridSolver, shape gs.TileSet, color gs.TileColor, joinNum int) bool {
-	shapeAsSlice := shape.Slice()
+func shouldPruneJoin(g GridSolver, shape gs.Assignment, color gs.TileColor, joinNum int) bool {
 	shapeCoords := shape.ToTileCoordSet()
 
 	var containsGoalTile bool
 	var containsTrineighborTile bool
 	var specialTiles int
-	for _, tile := range shapeAsSlice {
+	for _, coord := range shape.Coords() {
+		tile := *g.Grid.TileAtCoord(coord)
 
 		if tile.Data.Type != gs.TypeBlank {
 			specialTiles++
@@ -112,10 +121,10 @@ func shouldPruneJoin(g GridSolver, shape gs.TileSet, color gs.TileColor, joinNum
 	return false
 }
 
-func numSpecialTiles(g GridSolver, shape gs.TileSet, joinNum int) int {
+func numSpecialTiles(g GridSolver, shape gs.Assignment, joinNum int) int {
 	var numSpecialTiles int
-	for _, tile := range shape.Slice() {
-		if tile.Data.Type != gs.TypeBlank {
+	for _, coord := range shape.Coords() {
+		if g.Grid.TileAtCoord(coord).Data.Type != gs.TypeBlank {
 			numSpecialTiles++
 		}
 	}
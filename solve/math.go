@@ -1,5 +1,7 @@
 package solve
 
+import "context"
+
 // AllPairingSets returns all pairing sets for alphabet. for instance with limit=4, this would return something like:
 // [[0, 1] [2, 3]]
 // [[0, 2] [1, 3]]
@@ -41,23 +43,26 @@ func allPairingSetsForAlphabet(alphabet []int) [][][2]int {
 // Permutation returns a permutation with repetition of n (number of items) and r (size of container).
 // Returns an iterator function, which calls its argument for each permutation generated.
 func Permutation(n, r int) <-chan []int {
+	return PermutationContext(context.Background(), n, r)
+}
+
+// PermutationContext is like Permutation, but stops once ctx is cancelled.
+func PermutationContext(ctx context.Context, n, r int) <-chan []int {
 	iter := make(chan []int)
 
 	go func() {
 		defer close(iter)
 
-		// base case r==0, return a single nil slice
-		if r == 0 {
-			iter <- nil
-			return
-		}
-
-		for subPerm := range Permutation(n, r-1) {
-			for i := 0; i < n; i++ {
-				newPerm := make([]int, r)
-				newPerm[0] = i
-				copy(newPerm[1:], subPerm)
-				iter <- newPerm
+		perms := newPermutationIter(n, r)
+		for {
+			perm, ok := perms.next()
+			if !ok {
+				return
+			}
+			select {
+			case iter <- perm:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
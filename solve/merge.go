@@ -0,0 +1,532 @@
+package solve
+
+import (
+	"bufio"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// DefaultMergeMemoryLimit is the MemoryLimit used by merges whose MergeOptions do not set one.
+const DefaultMergeMemoryLimit = 1 << 22
+
+// maxMergePartitions is the most temporary files that each side of a merge is split into.
+const maxMergePartitions = 64
+
+// MergeOptions configures how solutions are merged by MergeSolutionsWith.
+type MergeOptions struct {
+	// MemoryLimit is about how many tiles a merge may hold in memory before it writes
+	// solutions to temporary files instead. If it is zero, DefaultMergeMemoryLimit is used,
+	// and if it is negative, everything is kept in memory.
+	MemoryLimit int
+
+	// TempDir is the directory that temporary files are created in. If it is empty,
+	// the default directory for temporary files is used.
+	TempDir string
+
+	// OnError is called if temporary files cannot be written or read, before the
+	// merge stops returning solutions. If it is nil, errors are ignored.
+	OnError func(err error)
+}
+
+// MergeSolutions is like MergeSolutionsIters, but for iterators. It uses the default MergeOptions.
+func MergeSolutions(ctx context.Context, left, right SolutionIterator) SolutionIterator {
+	return MergeSolutionsWith(ctx, left, right, MergeOptions{})
+}
+
+// MergeSolutionsWith makes pairs of solutions from left and right into a single solution,
+// as long as they do not assign different colors to the same tile.
+//
+// Every solution from right is read the first time Next is called, and indexed by its tiles
+// on the coordinates which all solutions from right contain. Solutions from left are then read
+// as needed, and only compared with the solutions from right that agree with them on those
+// coordinates. If the solutions from right hold more tiles than opts.MemoryLimit, both sides
+// are instead split into temporary files by the same index, and merged one file at a time.
+func MergeSolutionsWith(ctx context.Context, left, right SolutionIterator, opts MergeOptions) SolutionIterator {
+	if opts.MemoryLimit == 0 {
+		opts.MemoryLimit = DefaultMergeMemoryLimit
+	}
+	return &hashJoinIter{ctx: ctx, opts: opts, left: left, right: right}
+}
+
+// merge merges left and right using g.Merge.
+func (g GridSolver) merge(ctx context.Context, left, right SolutionIterator) SolutionIterator {
+	it := MergeSolutionsWith(ctx, left, right, g.Merge).(*hashJoinIter)
+	it.observer = g.Observer
+	return it
+}
+
+// hashJoinIter is the iterator returned by MergeSolutionsWith.
+type hashJoinIter struct {
+	ctx         context.Context
+	opts        MergeOptions
+	left, right SolutionIterator
+	observer    Observer
+
+	started bool
+	done    bool
+	closed  bool
+
+	// the solutions from right which are currently being merged, and the
+	// solutions from left which are being merged with them.
+	table    *joinTable
+	leftIter SolutionIterator
+
+	// the solution from left being merged, and its candidates from table
+	cur        gs.Assignment
+	candidates []int
+	index      int
+
+	// only used once solutions have been written to temporary files
+	dir        string
+	key        []gs.TileCoord
+	passes     []joinPass
+	leftFiles  []string
+	leftReader *spillReader
+}
+
+// joinPass is a partition of the solutions from right, and the files of
+// solutions from left which need to be merged with it.
+type joinPass struct {
+	right string
+	left  []string
+}
+
+func (it *hashJoinIter) Next() (gs.Assignment, bool) {
+	if !it.started {
+		it.started = true
+		if err := it.start(); err != nil {
+			it.fail(err)
+		}
+	}
+
+	for !it.done && it.ctx.Err() == nil {
+		for it.index < len(it.candidates) {
+			entry := it.table.entries[it.candidates[it.index]]
+			it.index++
+			it.observe(EventMergeAttempted)
+			if merged, ok := it.cur.Merged(entry); ok {
+				return merged, true
+			}
+			it.observe(EventMergeRejected)
+		}
+
+		solution, ok := it.leftIter.Next()
+		if !ok {
+			if err := it.nextLeft(); err != nil {
+				it.fail(err)
+			}
+			continue
+		}
+		it.cur = solution
+		it.candidates = it.table.candidates(solution)
+		it.index = 0
+	}
+	return gs.Assignment{}, false
+}
+
+func (it *hashJoinIter) Close() {
+	if it.closed {
+		return
+	}
+	it.closed, it.done = true, true
+	if !it.started {
+		it.right.Close()
+	}
+	it.left.Close()
+	if it.leftReader != nil {
+		it.leftReader.Close()
+		it.leftReader = nil
+	}
+	if it.dir != "" {
+		os.RemoveAll(it.dir)
+		it.dir = ""
+	}
+}
+
+func (it *hashJoinIter) observe(e Event) {
+	if it.observer != nil {
+		it.observer.Observe(e)
+	}
+}
+
+func (it *hashJoinIter) fail(err error) {
+	if it.opts.OnError != nil {
+		it.opts.OnError(err)
+	}
+	it.Close()
+}
+
+// start reads every solution from right, and writes them to temporary files if they
+// do not fit in memory.
+func (it *hashJoinIter) start() error {
+	defer it.right.Close()
+
+	var entries []gs.Assignment
+	var key gs.TileCoordSet
+	var numTiles, numSolutions int
+	var spill *spillWriter
+	defer func() {
+		if spill != nil {
+			spill.Close()
+		}
+	}()
+
+	for {
+		solution, ok := it.right.Next()
+		if !ok {
+			break
+		}
+		if it.ctx.Err() != nil {
+			return nil
+		}
+		if numSolutions == 0 {
+			key = solution.ToTileCoordSet()
+		} else {
+			key = intersectCoords(key, solution)
+		}
+		numTiles += solution.Len()
+		numSolutions++
+
+		if spill != nil {
+			if err := spill.Write(solution); err != nil {
+				return err
+			}
+			continue
+		}
+		entries = append(entries, solution)
+		if it.opts.MemoryLimit >= 0 && numTiles > it.opts.MemoryLimit {
+			dir, err := os.MkdirTemp(it.opts.TempDir, "gridspech-merge-*")
+			if err != nil {
+				return err
+			}
+			it.dir = dir
+			spill, err = createSpill(filepath.Join(dir, "right"))
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				if err := spill.Write(entry); err != nil {
+					return err
+				}
+			}
+			entries = nil
+		}
+	}
+
+	it.key = key.Slice()
+	if spill == nil {
+		it.table = newJoinTable(it.key, entries)
+		it.leftIter = it.left
+		if len(entries) == 0 {
+			it.done = true
+		}
+		return nil
+	}
+
+	if err := spill.Close(); err != nil {
+		spill = nil
+		return err
+	}
+	spill = nil
+
+	partitions := 2*numTiles/it.opts.MemoryLimit + 1
+	if partitions > maxMergePartitions {
+		partitions = maxMergePartitions
+	}
+	return it.partition(partitions)
+}
+
+// partition splits the solutions from right and left into temporary files by their
+// hash on the key, so that each partition of right can be merged separately.
+func (it *hashJoinIter) partition(partitions int) error {
+	rightFiles, err := it.partitionFile(filepath.Join(it.dir, "right"), "right", partitions)
+	if err != nil {
+		return err
+	}
+
+	// solutions from left which do not contain the whole key could match any partition
+	leftFiles := make([]*spillWriter, partitions)
+	for i := range leftFiles {
+		leftFiles[i], err = createSpill(filepath.Join(it.dir, fmt.Sprint("left", i)))
+		if err != nil {
+			return err
+		}
+		defer leftFiles[i].Close()
+	}
+	anyFile, err := createSpill(filepath.Join(it.dir, "left"))
+	if err != nil {
+		return err
+	}
+	defer anyFile.Close()
+
+	for {
+		solution, ok := it.left.Next()
+		if !ok || it.ctx.Err() != nil {
+			break
+		}
+		writer := anyFile
+		if hash, ok := keyHash(it.key, solution); ok {
+			writer = leftFiles[hash%uint64(partitions)]
+		}
+		if err := writer.Write(solution); err != nil {
+			return err
+		}
+	}
+	for _, file := range append(leftFiles, anyFile) {
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	for i, rightFile := range rightFiles {
+		it.passes = append(it.passes, joinPass{
+			right: rightFile,
+			left:  []string{leftFiles[i].name, anyFile.name},
+		})
+	}
+	it.table = newJoinTable(it.key, nil)
+	it.leftIter = emptyIter()
+	return nil
+}
+
+// partitionFile splits the solutions in the file at path into partitions by their hash on the key.
+// Files which would not contain any solutions are not returned.
+func (it *hashJoinIter) partitionFile(path, prefix string, partitions int) ([]string, error) {
+	reader, err := openSpill(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	writers := make([]*spillWriter, partitions)
+	defer func() {
+		for _, writer := range writers {
+			if writer != nil {
+				writer.Close()
+			}
+		}
+	}()
+
+	for {
+		solution, ok := reader.Next()
+		if !ok {
+			break
+		}
+		hash, _ := keyHash(it.key, solution)
+		i := hash % uint64(partitions)
+		if writers[i] == nil {
+			writers[i], err = createSpill(filepath.Join(it.dir, fmt.Sprint(prefix, i)))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := writers[i].Write(solution); err != nil {
+			return nil, err
+		}
+	}
+	if reader.err != nil {
+		return nil, reader.err
+	}
+
+	files := make([]string, partitions)
+	for i, writer := range writers {
+		if writer == nil {
+			continue
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		files[i] = writer.name
+	}
+	os.Remove(path)
+	return files, nil
+}
+
+// nextLeft moves on to the next file of solutions from left, loading the
+// next partition of right if needed.
+func (it *hashJoinIter) nextLeft() error {
+	if it.leftReader != nil {
+		it.leftReader.Close()
+		if it.leftReader.err != nil {
+			return it.leftReader.err
+		}
+		it.leftReader = nil
+	}
+	it.candidates = nil
+
+	for len(it.leftFiles) == 0 {
+		if len(it.passes) == 0 {
+			it.Close()
+			return nil
+		}
+		pass := it.passes[0]
+		it.passes = it.passes[1:]
+		if pass.right == "" {
+			continue
+		}
+
+		reader, err := openSpill(pass.right)
+		if err != nil {
+			return err
+		}
+		entries := CollectSolutions(reader)
+		if reader.err != nil {
+			return reader.err
+		}
+		it.table = newJoinTable(it.key, entries)
+		it.leftFiles = pass.left
+	}
+
+	reader, err := openSpill(it.leftFiles[0])
+	if err != nil {
+		return err
+	}
+	it.leftFiles = it.leftFiles[1:]
+	it.leftReader = reader
+	it.leftIter = reader
+	return nil
+}
+
+// joinTable is an index of solutions by their hash on a key.
+type joinTable struct {
+	key     []gs.TileCoord
+	entries []gs.Assignment
+	buckets map[uint64][]int
+	all     []int
+}
+
+func newJoinTable(key []gs.TileCoord, solutions []gs.Assignment) *joinTable {
+	table := &joinTable{key: key, entries: solutions, buckets: make(map[uint64][]int)}
+	for i, solution := range solutions {
+		hash, _ := keyHash(key, solution)
+		table.buckets[hash] = append(table.buckets[hash], i)
+		table.all = append(table.all, i)
+	}
+	return table
+}
+
+// candidates returns the entries which may be able to merge with a solution. If the
+// solution does not contain the whole key, every entry is a candidate.
+func (t *joinTable) candidates(solution gs.Assignment) []int {
+	hash, ok := keyHash(t.key, solution)
+	if !ok {
+		return t.all
+	}
+	return t.buckets[hash]
+}
+
+// keyHash hashes the color at each coordinate of key. It returns false
+// if solution does not contain every coordinate.
+func keyHash(key []gs.TileCoord, solution gs.Assignment) (uint64, bool) {
+	h := fnv.New64a()
+	for _, coord := range key {
+		color, ok := solution.Get(coord)
+		if !ok {
+			return 0, false
+		}
+		h.Write([]byte{byte(color)})
+	}
+	return h.Sum64(), true
+}
+
+// intersectCoords returns the coordinates in coords which solution also contains.
+func intersectCoords(coords gs.TileCoordSet, solution gs.Assignment) gs.TileCoordSet {
+	result := gs.NewTileCoordSet()
+	for _, coord := range coords.Slice() {
+		if solution.Has(coord) {
+			result.Add(coord)
+		}
+	}
+	return result
+}
+
+// spillRecord is how a solution is written to a temporary file.
+type spillRecord struct {
+	Coords []gs.TileCoord
+	Colors []gs.TileColor
+}
+
+type spillWriter struct {
+	name   string
+	file   *os.File
+	buf    *bufio.Writer
+	enc    *gob.Encoder
+	closed bool
+}
+
+func createSpill(name string) (*spillWriter, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(file)
+	return &spillWriter{name: name, file: file, buf: buf, enc: gob.NewEncoder(buf)}, nil
+}
+
+func (w *spillWriter) Write(solution gs.Assignment) error {
+	var record spillRecord
+	for _, coord := range solution.Coords() {
+		color, _ := solution.Get(coord)
+		record.Coords = append(record.Coords, coord)
+		record.Colors = append(record.Colors, color)
+	}
+	return w.enc.Encode(record)
+}
+
+// Close flushes and closes the file. It may be called more than once.
+func (w *spillWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.buf.Flush()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// spillReader is a SolutionIterator over the solutions in a temporary file.
+// If the file cannot be read, Next returns false and err is set.
+type spillReader struct {
+	file *os.File
+	dec  *gob.Decoder
+	err  error
+}
+
+func openSpill(name string) (*spillReader, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &spillReader{file: file, dec: gob.NewDecoder(bufio.NewReader(file))}, nil
+}
+
+func (r *spillReader) Next() (gs.Assignment, bool) {
+	if r.err != nil {
+		return gs.Assignment{}, false
+	}
+	var record spillRecord
+	if err := r.dec.Decode(&record); err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		return gs.Assignment{}, false
+	}
+	var solution gs.Assignment
+	for i, coord := range record.Coords {
+		solution.Set(coord, record.Colors[i])
+	}
+	return solution, true
+}
+
+func (r *spillReader) Close() {
+	r.file.Close()
+}
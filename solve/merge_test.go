@@ -0,0 +1,123 @@
+package solve_test
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+// randomSolutions returns solutions which each color a random subset of a 3x2 grid, always
+// including the tiles in required.
+func randomSolutions(r *rand.Rand, n int, required ...gs.TileCoord) []gs.Assignment {
+	solutions := make([]gs.Assignment, n)
+	for i := range solutions {
+		for x := 0; x < 3; x++ {
+			for y := 0; y < 2; y++ {
+				coord := gs.TileCoord{X: x, Y: y}
+				isRequired := false
+				for _, req := range required {
+					isRequired = isRequired || req == coord
+				}
+				if isRequired || r.Intn(2) == 0 {
+					solutions[i].Set(coord, gs.TileColor(r.Intn(2)))
+				}
+			}
+		}
+	}
+	return solutions
+}
+
+// naiveMerge merges every compatible pair of solutions.
+func naiveMerge(sols1, sols2 []gs.Assignment) []gs.Assignment {
+	var result []gs.Assignment
+	for _, sol1 := range sols1 {
+		for _, sol2 := range sols2 {
+			if merged, ok := sol1.Merged(sol2); ok {
+				result = append(result, merged)
+			}
+		}
+	}
+	return result
+}
+
+func sliceIterator(solutions []gs.Assignment) solve.SolutionIterator {
+	ch := make(chan gs.Assignment, len(solutions))
+	for _, solution := range solutions {
+		ch <- solution
+	}
+	close(ch)
+	return solve.ChanToIter(ch)
+}
+
+func TestMergeSolutionsWith(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 100; iter++ {
+		left := randomSolutions(r, r.Intn(20))
+		right := randomSolutions(r, r.Intn(20), gs.TileCoord{X: 0, Y: 0})
+		expected := naiveMerge(left, right)
+
+		for _, limit := range []int{-1, 1, 10} {
+			dir := t.TempDir()
+			var mergeErr error
+			opts := solve.MergeOptions{MemoryLimit: limit, TempDir: dir, OnError: func(err error) { mergeErr = err }}
+			actual := solve.CollectSolutions(solve.MergeSolutionsWith(context.Background(), sliceIterator(left), sliceIterator(right), opts))
+			if mergeErr != nil {
+				t.Fatal(mergeErr)
+			}
+			if len(actual) != len(expected) {
+				t.Fatalf("expected %d solutions with limit %d, got %d", len(expected), limit, len(actual))
+			}
+			testUnorderedAssignmentSliceEq(t, expected, actual)
+
+			files, _ := filepath.Glob(filepath.Join(dir, "*"))
+			if len(files) > 0 {
+				t.Fatalf("expected temporary files to be removed, found %v", files)
+			}
+		}
+	}
+}
+
+func TestMergeSolutionsWith_tempDirError(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	solutions := randomSolutions(r, 10)
+
+	var mergeErr error
+	opts := solve.MergeOptions{
+		MemoryLimit: 1,
+		TempDir:     filepath.Join(t.TempDir(), "does-not-exist"),
+		OnError:     func(err error) { mergeErr = err },
+	}
+	merged := solve.MergeSolutionsWith(context.Background(), sliceIterator(solutions), sliceIterator(solutions), opts)
+	if solutions := solve.CollectSolutions(merged); len(solutions) > 0 {
+		t.Errorf("expected no solutions, got %d", len(solutions))
+	}
+	if !os.IsNotExist(mergeErr) {
+		t.Errorf("expected a not exist error, got %v", mergeErr)
+	}
+}
+
+func TestSolveAllTiles_mergeMemoryLimit(t *testing.T) {
+	const level = `
+	0m1  0   0   0e
+	0    0m2 0   0
+	0e   0   0   0m1
+	`
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	expected := solvedGrids(solver)
+
+	solver.Merge = solve.MergeOptions{MemoryLimit: 1, TempDir: t.TempDir()}
+	actual := solvedGrids(solver)
+	if len(expected) == 0 || len(actual) != len(expected) {
+		t.Errorf("expected %d solutions, got %d", len(expected), len(actual))
+	}
+	for grid := range actual {
+		if _, ok := expected[grid]; !ok {
+			t.Errorf("incorrect solution\n%v", grid)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package solve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Event is something that happened while solving, which is reported to an Observer.
+type Event int
+
+const (
+	// EventShapeGenerated happens when a shape is generated for a crown or join.
+	EventShapeGenerated Event = iota
+	// EventShapePruned happens when a shape is not grown any further, because
+	// no larger shape could be a solution.
+	EventShapePruned
+	// EventPathFound happens when a path is found between two goals.
+	EventPathFound
+	// EventMergeAttempted happens when two solutions are compared to be merged together.
+	EventMergeAttempted
+	// EventMergeRejected happens when two solutions cannot be merged together,
+	// because they assign different colors to the same tile.
+	EventMergeRejected
+	// EventInvalidCandidate happens when a candidate solution is thrown away
+	// because the grid is not valid once it is applied.
+	EventInvalidCandidate
+
+	numEvents
+)
+
+func (e Event) String() string {
+	switch e {
+	case EventShapeGenerated:
+		return "shapes generated"
+	case EventShapePruned:
+		return "shapes pruned"
+	case EventPathFound:
+		return "paths found"
+	case EventMergeAttempted:
+		return "merges attempted"
+	case EventMergeRejected:
+		return "merges rejected"
+	case EventInvalidCandidate:
+		return "invalid candidates"
+	default:
+		return fmt.Sprintf("Event(%d)", int(e))
+	}
+}
+
+// Observer is told about events that happen while solving, which can be used to see how
+// the solver is progressing. Observe may be called from multiple goroutines at once, and
+// should return quickly, since it is called from the solver's inner loops.
+type Observer interface {
+	Observe(e Event)
+}
+
+// observe reports e to g.Observer, if there is one.
+func (g GridSolver) observe(e Event) {
+	if g.Observer != nil {
+		g.Observer.Observe(e)
+	}
+}
+
+// Stats is an Observer which counts each kind of event. It is safe for concurrent use,
+// and its zero value has not counted any events.
+type Stats struct {
+	counts [numEvents]int64
+}
+
+// Observe implements Observer.
+func (s *Stats) Observe(e Event) {
+	if e >= 0 && e < numEvents {
+		atomic.AddInt64(&s.counts[e], 1)
+	}
+}
+
+// Count returns the number of times that e has been observed.
+func (s *Stats) Count(e Event) int64 {
+	if e < 0 || e >= numEvents {
+		return 0
+	}
+	return atomic.LoadInt64(&s.counts[e])
+}
+
+// String returns the count of each event on a single line, ie "shapes generated: 5, shapes pruned: 2, ...".
+func (s *Stats) String() string {
+	counts := make([]string, numEvents)
+	for e := Event(0); e < numEvents; e++ {
+		counts[e] = fmt.Sprintf("%v: %d", e, s.Count(e))
+	}
+	return strings.Join(counts, ", ")
+}
+
+// PrintProgress writes stats to w once every interval, until ctx is cancelled.
+func PrintProgress(ctx context.Context, w io.Writer, interval time.Duration, stats *Stats) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	start := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			fmt.Fprintf(w, "[%v] %v\n", now.Sub(start).Round(time.Second), stats)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package solve_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func testObserve(t *testing.T, level string, expectedEvents ...solve.Event) {
+	t.Helper()
+
+	var stats solve.Stats
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	solver.Observer = &stats
+	solve.CollectSolutions(solver.IterateAllTiles(context.Background()))
+
+	for _, e := range expectedEvents {
+		if stats.Count(e) == 0 {
+			t.Errorf("expected %v to be observed, got %v", e, &stats)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	testObserve(t, `
+	0e  0   0e  0
+	0   0   0   0
+	0e  0   0   0e
+	0   0k  0   0j1
+	`, solve.EventShapeGenerated, solve.EventPathFound, solve.EventInvalidCandidate)
+
+	// shapes are only pruned here for candidates which do not already violate a tile
+	testObserve(t, `
+	0e   0   0  0e
+	0    0k  0  0
+	0j1  0   0  0j1
+	`, solve.EventShapeGenerated, solve.EventShapePruned, solve.EventPathFound, solve.EventMergeAttempted, solve.EventInvalidCandidate)
+
+	testObserve(t, `
+	0e  0    0  0e
+	0   0m2  0  0
+	0   0    0  0
+	`, solve.EventPathFound)
+
+	// goals and dots are separate constraints, so solutions are only merged between dots
+	testObserve(t, `
+	0m1  0    0m2
+	0    0m1  0
+	`, solve.EventMergeAttempted, solve.EventMergeRejected)
+}
+
+func TestPrintProgress(t *testing.T) {
+	var stats solve.Stats
+	stats.Observe(solve.EventPathFound)
+	stats.Observe(solve.EventPathFound)
+
+	var buf bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	solve.PrintProgress(ctx, &buf, 10*time.Millisecond, &stats)
+
+	if !strings.Contains(buf.String(), "paths found: 2, merges attempted: 0") {
+		t.Errorf("expected progress to contain the stats, got %q", buf.String())
+	}
+}
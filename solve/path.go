@@ -1,6 +1,8 @@
 package solve
 
 import (
+	"context"
+
 	gs "github.com/deanveloper/gridspech-go"
 )
 
@@ -9,34 +11,71 @@ import (
 //   1. never contain a goal tile that isn't start or end.
 //   2. never make a path that would cause start or end to become invalid Goal tiles.
 //   3. have the same Color as start.
-func (g GridSolver) PathsIter(start, end gs.TileCoord, color gs.TileColor) <-chan gs.TileSet {
-	pathIter := make(chan gs.TileSet)
-	go func() {
-		defer close(pathIter)
-		startTile, endTile := *g.Grid.TileAtCoord(start), *g.Grid.TileAtCoord(end)
-		if !g.UnknownTiles.Has(start) && color != startTile.Data.Color {
-			return
-		}
-		if !g.UnknownTiles.Has(end) && color != endTile.Data.Color {
-			return
-		}
-		g.dfsDirectPaths(color, startTile, endTile, gs.NewTileCoordSet(start), pathIter)
-	}()
+func (g GridSolver) PathsIter(start, end gs.TileCoord, color gs.TileColor) <-chan gs.Assignment {
+	return g.PathsIterContext(context.Background(), start, end, color)
+}
 
-	return pathIter
+// PathsIterContext is like PathsIter, but stops once ctx is cancelled.
+func (g GridSolver) PathsIterContext(ctx context.Context, start, end gs.TileCoord, color gs.TileColor) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IteratePaths(ctx, start, end, color))
 }
 
+// IteratePaths is like PathsIterContext, but returns an iterator.
+func (g GridSolver) IteratePaths(ctx context.Context, start, end gs.TileCoord, color gs.TileColor) SolutionIterator {
+	startTile, endTile := *g.Grid.TileAtCoord(start), *g.Grid.TileAtCoord(end)
+	if !g.UnknownTiles.Has(start) && color != startTile.Data.Color {
+		return emptyIter()
+	}
+	if !g.UnknownTiles.Has(end) && color != endTile.Data.Color {
+		return emptyIter()
+	}
+
+	it := &pathIter{ctx: ctx, g: g, color: color, end: endTile}
+	it.push(startTile, gs.NewTileCoordSet(start))
+	return it
+}
+
+// pathIter is a depth-first search for direct paths, which keeps its own stack so
+// that it can stop after each path that it finds.
 // we do not iterate in any particular order since it does not matter.
-// this function will only create direct paths, aka ones which would satisfy
-// a Goal tile.
-func (g GridSolver) dfsDirectPaths(color gs.TileColor, prev, end gs.Tile, path gs.TileCoordSet, ch chan<- gs.TileSet) {
+// this will only create direct paths, aka ones which would satisfy a Goal tile.
+type pathIter struct {
+	ctx   context.Context
+	g     GridSolver
+	color gs.TileColor
+	end   gs.Tile
+	stack []pathFrame
+}
+
+type pathFrame struct {
+	prev         gs.Tile
+	path         gs.TileCoordSet
+	possibleNext []gs.Tile
+}
+
+func (it *pathIter) push(prev gs.Tile, path gs.TileCoordSet) {
+	g, color := it.g, it.color
 
 	// possible next tiles include unknown tiles, and tiles of the target color
 	possibleNext := g.Grid.NeighborSetWith(prev.Coord, func(o gs.Tile) bool {
 		return !path.Has(o.Coord) && (g.UnknownTiles.Has(o.Coord) || o.Data.Color == color)
 	})
+	it.stack = append(it.stack, pathFrame{prev: prev, path: path, possibleNext: possibleNext.Slice()})
+}
+
+func (it *pathIter) Next() (gs.Assignment, bool) {
+	g, color, end := it.g, it.color, it.end
+
+	for len(it.stack) > 0 && it.ctx.Err() == nil {
+		frame := &it.stack[len(it.stack)-1]
+		if len(frame.possibleNext) == 0 {
+			it.stack = it.stack[:len(it.stack)-1]
+			continue
+		}
+		prev, path, next := frame.prev, frame.path, frame.possibleNext[0]
+		frame.possibleNext = frame.possibleNext[1:]
+		spendNodes(it.ctx, 1)
 
-	for _, next := range possibleNext.Slice() {
 		// prev's neighbors we _know_ are same color (including those that are part of the path)
 		prevNeighborsSameColor := g.Grid.NeighborSetWith(prev.Coord, func(o gs.Tile) bool {
 			knownSameColor := (o.Data.Color == color && !g.UnknownTiles.Has(o.Coord))
@@ -68,22 +107,20 @@ func (g GridSolver) dfsDirectPaths(color gs.TileColor, prev, end gs.Tile, path g
 				}
 			}
 
-			finalPath := path.ToTileSet(func(t gs.TileCoord) gs.Tile {
-				tileCopy := *g.Grid.TileAtCoord(t)
-				tileCopy.Data.Color = color
-				return tileCopy
-			})
-			next.Data.Color = color
-			finalPath.Add(next)
-			ch <- finalPath
-			continue
+			finalPath := assignColor(path, color)
+			finalPath.Set(next.Coord, color)
+			g.observe(EventPathFound)
+			return finalPath, true
 		}
 
 		var nextPath gs.TileCoordSet
 		nextPath.Merge(path)
 		nextPath.Add(next.Coord)
-
-		// RECURSION
-		g.dfsDirectPaths(color, next, end, nextPath, ch)
+		it.push(next, nextPath)
 	}
+	return gs.Assignment{}, false
+}
+
+func (it *pathIter) Close() {
+	it.stack = nil
 }
@@ -13,18 +13,18 @@ func testPathsIterAbstract(t *testing.T, level string, x1, y1, x2, y2 int, solut
 
 	grid := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
 
-	var expectedSolutions []gs.TileSet
+	var expectedSolutions []gs.Assignment
 	for i := range solutions {
-		expectedSolutions = append(expectedSolutions, tileSetFromString(grid.Grid, solutions[i]))
+		expectedSolutions = append(expectedSolutions, assignmentFromString(grid.Grid, solutions[i]))
 	}
 
 	ch := grid.PathsIter(gs.TileCoord{X: x1, Y: y1}, gs.TileCoord{X: x2, Y: y2}, 1)
-	var actualSolutions []gs.TileSet
+	var actualSolutions []gs.Assignment
 	for ts := range ch {
 		actualSolutions = append(actualSolutions, ts)
 	}
 
-	testUnorderedTilesetSliceEq(t, expectedSolutions, actualSolutions)
+	testUnorderedAssignmentSliceEq(t, expectedSolutions, actualSolutions)
 }
 
 func TestPathsIter_levelA1(t *testing.T) {
@@ -90,25 +90,20 @@ func TestPathsIter_basicColorNonePath(t *testing.T) {
 	grid := gs.MakeGridFromString(level, 2)
 	gridSolver := solve.NewGridSolver(grid)
 	solutionsChan := gridSolver.PathsIter(gs.TileCoord{X: 0, Y: 1}, gs.TileCoord{X: 3, Y: 1}, gs.ColorNone)
-	var solutions []gs.TileSet
+	var solutions []gs.Assignment
 	for solution := range solutionsChan {
 		solutions = append(solutions, solution)
 	}
 	if len(solutions) != 1 {
 		t.Fatalf("solutions length expected to be 1 but was %d", len(solutions))
 	}
-	expected := gs.NewTileSet(
-		gs.Tile{Coord: gs.TileCoord{X: 0, Y: 1}, Data: gs.TileData{Sticky: true, Type: gs.TypeGoal}},
-		gs.Tile{Coord: gs.TileCoord{X: 1, Y: 1}, Data: gs.TileData{Type: gs.TypeBlank}},
-		gs.Tile{Coord: gs.TileCoord{X: 2, Y: 1}, Data: gs.TileData{Type: gs.TypeBlank}},
-		gs.Tile{Coord: gs.TileCoord{X: 3, Y: 1}, Data: gs.TileData{Type: gs.TypeGoal}},
-	)
+	expected := assignmentFromString(grid, "0000|")
 
 	if !expected.Eq(solutions[0]) {
 		expectedGrid := grid.Clone()
 		actualGrid := grid.Clone()
-		expectedGrid.ApplyTileSet(expected)
-		actualGrid.ApplyTileSet(solutions[0])
+		expected.Apply(expectedGrid)
+		solutions[0].Apply(actualGrid)
 		t.Errorf("solutions not equal")
 		t.Errorf("expected:\n%v", expectedGrid)
 		t.Errorf("actual:\n%v", actualGrid)
@@ -0,0 +1,132 @@
+package solve
+
+import (
+	"fmt"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// Pin requires the tile at coord to have color in every solution. The tile is given the color
+// and is no longer unknown, just like a sticky tile. An error is returned if the tile is a hole,
+// if color is not less than g.Grid.MaxColors, or if the tile is already known with another color.
+func (g *GridSolver) Pin(coord gs.TileCoord, color gs.TileColor) error {
+	if err := g.checkTileColor(coord, color); err != nil {
+		return err
+	}
+	tile := g.Grid.TileAtCoord(coord)
+	if !g.UnknownTiles.Has(coord) && tile.Data.Color != color {
+		return fmt.Errorf("cannot pin %v to color %v, it is already known to have color %v", coord, color, tile.Data.Color)
+	}
+	if g.Forbidden[coord].Has(color) {
+		return fmt.Errorf("cannot pin %v to color %v, the color is forbidden", coord, color)
+	}
+
+	tile.Data.Color = color
+	g.UnknownTiles.Remove(coord)
+	return nil
+}
+
+// Forbid requires the tile at coord to not have color in any solution. An error is returned
+// if the tile is a hole, if color is not less than g.Grid.MaxColors, or if the tile is already
+// known to have color.
+func (g *GridSolver) Forbid(coord gs.TileCoord, color gs.TileColor) error {
+	if err := g.checkTileColor(coord, color); err != nil {
+		return err
+	}
+	if !g.UnknownTiles.Has(coord) && g.Grid.TileAtCoord(coord).Data.Color == color {
+		return fmt.Errorf("cannot forbid color %v at %v, it is already known to have that color", color, coord)
+	}
+
+	forbidden := make(map[gs.TileCoord]ColorSet, len(g.Forbidden)+1)
+	for c, colors := range g.Forbidden {
+		forbidden[c] = colors
+	}
+	forbidden[coord] |= SingleColor(color)
+	g.Forbidden = forbidden
+	return nil
+}
+
+// PairGoals requires the goals at a and b to be connected to each other in every solution.
+// An error is returned if either tile is not a goal, or if either is already paired with
+// a different goal.
+func (g *GridSolver) PairGoals(a, b gs.TileCoord) error {
+	for _, coord := range []gs.TileCoord{a, b} {
+		if !g.inBounds(coord) || g.Grid.TileAtCoord(coord).Data.Type != gs.TypeGoal {
+			return fmt.Errorf("cannot pair %v with %v, %v is not a goal", a, b, coord)
+		}
+	}
+	if a == b {
+		return fmt.Errorf("cannot pair %v with itself", a)
+	}
+	for _, coord := range []gs.TileCoord{a, b} {
+		if partner, ok := g.goalPartner(coord); ok && partner != a && partner != b {
+			return fmt.Errorf("cannot pair %v with %v, %v is already paired with %v", a, b, coord, partner)
+		}
+	}
+	if partner, ok := g.goalPartner(a); ok && partner == b {
+		return nil
+	}
+
+	g.GoalPairs = append(append([][2]gs.TileCoord(nil), g.GoalPairs...), [2]gs.TileCoord{a, b})
+	return nil
+}
+
+// checkTileColor returns an error if coord is not a tile in g, or if color cannot be used in g.
+func (g GridSolver) checkTileColor(coord gs.TileCoord, color gs.TileColor) error {
+	if !g.inBounds(coord) || g.Grid.TileAtCoord(coord).Data.Type == gs.TypeHole {
+		return fmt.Errorf("%v is not a tile", coord)
+	}
+	if int(color) >= g.Grid.MaxColors {
+		return fmt.Errorf("color %v is not less than the max of %d colors", color, g.Grid.MaxColors)
+	}
+	return nil
+}
+
+func (g GridSolver) inBounds(coord gs.TileCoord) bool {
+	return coord.X >= 0 && coord.X < g.Grid.Width() && coord.Y >= 0 && coord.Y < g.Grid.Height()
+}
+
+// allowedColors returns the colors that the tile at coord may have in a solution.
+func (g GridSolver) allowedColors(coord gs.TileCoord) ColorSet {
+	return AllColors(g.Grid.MaxColors) &^ g.Forbidden[coord]
+}
+
+// goalPartner returns the goal which the goal at coord was paired with by PairGoals.
+func (g GridSolver) goalPartner(coord gs.TileCoord) (gs.TileCoord, bool) {
+	for _, pair := range g.GoalPairs {
+		switch coord {
+		case pair[0]:
+			return pair[1], true
+		case pair[1]:
+			return pair[0], true
+		}
+	}
+	return gs.TileCoord{}, false
+}
+
+// followsPairs returns if each pair of goals in g.GoalPairs is connected in solved.
+func (g GridSolver) followsPairs(solved gs.Grid) bool {
+	for _, pair := range g.GoalPairs {
+		if !solved.Blob(pair[0]).Has(*solved.TileAtCoord(pair[1])) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasPairing returns if pairing contains every pair of goals in g.GoalPairs.
+func (g GridSolver) hasPairing(pairing [][2]gs.TileCoord) bool {
+	for _, required := range g.GoalPairs {
+		var found bool
+		for _, pair := range pairing {
+			if pair == required || pair == [2]gs.TileCoord{required[1], required[0]} {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
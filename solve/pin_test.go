@@ -0,0 +1,242 @@
+package solve_test
+
+import (
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestGridSolver_inputs(t *testing.T) {
+	const level = `
+	0e  0  0e
+	0   0  0
+	0e  0  0e
+	`
+	cases := []struct {
+		name   string
+		pin    map[gs.TileCoord]gs.TileColor
+		forbid map[gs.TileCoord]gs.TileColor
+		pairs  [][2]gs.TileCoord
+	}{
+		{name: "none"},
+		{name: "pin", pin: map[gs.TileCoord]gs.TileColor{{X: 1, Y: 2}: 1}},
+		{name: "forbid", forbid: map[gs.TileCoord]gs.TileColor{{X: 1, Y: 1}: 0, {X: 0, Y: 0}: 1}},
+		{name: "pair", pairs: [][2]gs.TileCoord{{{X: 0, Y: 0}, {X: 0, Y: 2}}}},
+		{
+			name:   "all",
+			pin:    map[gs.TileCoord]gs.TileColor{{X: 0, Y: 1}: 1},
+			forbid: map[gs.TileCoord]gs.TileColor{{X: 2, Y: 1}: 0},
+			pairs:  [][2]gs.TileCoord{{{X: 2, Y: 2}, {X: 2, Y: 0}}},
+		},
+	}
+	engines := []struct {
+		name   string
+		engine solve.Engine
+	}{
+		{name: "pipeline"},
+		{name: "propagate", engine: solve.PropagationEngine{}},
+		{name: "sat", engine: solve.SATEngine{OnError: func(err error) { t.Error(err) }}},
+	}
+
+	for _, c := range cases {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+		for coord, color := range c.pin {
+			if err := solver.Pin(coord, color); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for coord, color := range c.forbid {
+			if err := solver.Forbid(coord, color); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, pair := range c.pairs {
+			if err := solver.PairGoals(pair[0], pair[1]); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// every coloring of the grid which is valid and follows the inputs
+		expected := make(map[string]struct{})
+		for bits := 0; bits < 1<<9; bits++ {
+			grid := solver.Grid.Clone()
+			for i := 0; i < 9; i++ {
+				grid.TileAt(i%3, i/3).Data.Color = gs.TileColor(bits >> i & 1)
+			}
+			if !grid.Valid() {
+				continue
+			}
+			follows := true
+			for coord, color := range c.pin {
+				follows = follows && grid.TileAtCoord(coord).Data.Color == color
+			}
+			for coord, color := range c.forbid {
+				follows = follows && grid.TileAtCoord(coord).Data.Color != color
+			}
+			for _, pair := range c.pairs {
+				follows = follows && grid.Blob(pair[0]).Has(*grid.TileAtCoord(pair[1]))
+			}
+			if follows {
+				expected[grid.String()] = struct{}{}
+			}
+		}
+		if len(expected) == 0 {
+			t.Fatalf("%s: expected the level to have solutions", c.name)
+		}
+
+		for _, e := range engines {
+			solver.Engine = e.engine
+			actual := solvedGrids(solver)
+			if len(actual) != len(expected) {
+				t.Errorf("%s/%s: expected %d solutions, got %d", c.name, e.name, len(expected), len(actual))
+			}
+			for grid := range actual {
+				if _, ok := expected[grid]; !ok {
+					t.Errorf("%s/%s: incorrect solution\n%v", c.name, e.name, grid)
+				}
+			}
+		}
+	}
+}
+
+func TestGridSolver_inputErrors(t *testing.T) {
+	solver := solve.NewGridSolver(gs.MakeGridFromString("0e  _  0\n0  0  0e", 2))
+
+	if err := solver.Pin(gs.TileCoord{X: 1, Y: 1}, 0); err == nil {
+		t.Error("expected error when pinning a hole")
+	}
+	if err := solver.Pin(gs.TileCoord{X: 0, Y: 0}, 2); err == nil {
+		t.Error("expected error when pinning a color past MaxColors")
+	}
+	if err := solver.Forbid(gs.TileCoord{X: 3, Y: 0}, 0); err == nil {
+		t.Error("expected error when forbidding a color outside of the grid")
+	}
+	if err := solver.PairGoals(gs.TileCoord{X: 0, Y: 1}, gs.TileCoord{X: 1, Y: 0}); err == nil {
+		t.Error("expected error when pairing a goal with a blank tile")
+	}
+
+	if err := solver.Pin(gs.TileCoord{X: 1, Y: 0}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := solver.Pin(gs.TileCoord{X: 1, Y: 0}, 0); err == nil {
+		t.Error("expected error when pinning a tile to a second color")
+	}
+	if err := solver.Forbid(gs.TileCoord{X: 1, Y: 0}, 1); err == nil {
+		t.Error("expected error when forbidding the color of a pinned tile")
+	}
+
+	if err := solver.Forbid(gs.TileCoord{X: 2, Y: 1}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := solver.Pin(gs.TileCoord{X: 2, Y: 1}, 0); err == nil {
+		t.Error("expected error when pinning a tile to a forbidden color")
+	}
+
+	// inputs are not shared with clones
+	clone := solver.Clone()
+	if err := clone.Forbid(gs.TileCoord{X: 0, Y: 0}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := solver.Forbidden[gs.TileCoord{X: 0, Y: 0}]; ok {
+		t.Error("expected Forbid on a clone to not change the original")
+	}
+}
+
+func TestGridSolver_inputsEntryPoints(t *testing.T) {
+	const goals = `
+	0e  0  0e
+	0   0  0
+	0e  0  0e
+	`
+	center := gs.TileCoord{X: 1, Y: 1}
+	cases := []struct {
+		name  string
+		level string
+		setup func(g *solve.GridSolver) error
+		keep  func(solved gs.Grid) bool
+		solve func(g solve.GridSolver) <-chan gs.Assignment
+	}{
+		{
+			name:  "goals",
+			level: goals,
+			setup: func(g *solve.GridSolver) error { return g.Forbid(center, 1) },
+			keep:  func(solved gs.Grid) bool { return solved.TileAtCoord(center).Data.Color != 1 },
+			solve: solve.GridSolver.SolveGoals,
+		},
+		{
+			name:  "goal pairs",
+			level: goals,
+			setup: func(g *solve.GridSolver) error {
+				return g.PairGoals(gs.TileCoord{X: 0, Y: 0}, gs.TileCoord{X: 0, Y: 2})
+			},
+			keep: func(solved gs.Grid) bool {
+				return solved.Blob(gs.TileCoord{X: 0, Y: 0}).Has(*solved.TileAt(0, 2))
+			},
+			solve: solve.GridSolver.SolveGoals,
+		},
+		{
+			name:  "dots",
+			level: "0  0m2  0\n0  0  0",
+			setup: func(g *solve.GridSolver) error { return g.Forbid(gs.TileCoord{X: 0, Y: 1}, 1) },
+			keep:  func(solved gs.Grid) bool { return solved.TileAt(0, 1).Data.Color != 1 },
+			solve: solve.GridSolver.SolveDots,
+		},
+		{
+			name:  "joins",
+			level: "0j1  0  0\n0  0  0j1",
+			setup: func(g *solve.GridSolver) error { return g.Forbid(gs.TileCoord{X: 1, Y: 1}, 1) },
+			keep:  func(solved gs.Grid) bool { return solved.TileAt(1, 1).Data.Color != 1 },
+			solve: solve.GridSolver.SolveJoins,
+		},
+		{
+			name:  "crowns",
+			level: "0  0  0k",
+			setup: func(g *solve.GridSolver) error { return g.Forbid(gs.TileCoord{X: 1, Y: 0}, 1) },
+			keep:  func(solved gs.Grid) bool { return solved.TileAt(1, 0).Data.Color != 1 },
+			solve: solve.GridSolver.SolveCrowns,
+		},
+		{
+			name:  "tiles",
+			level: goals,
+			setup: func(g *solve.GridSolver) error { return g.Forbid(center, 1) },
+			keep:  func(solved gs.Grid) bool { return solved.TileAtCoord(center).Data.Color != 1 },
+			solve: func(g solve.GridSolver) <-chan gs.Assignment {
+				return g.SolveTiles(gs.TileCoord{X: 0, Y: 0}, gs.TileCoord{X: 2, Y: 2})
+			},
+		},
+	}
+
+	for _, c := range cases {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(c.level, 2))
+
+		// the solutions without the input, which follow it
+		expected := make(map[string]struct{})
+		var all int
+		for solution := range c.solve(solver) {
+			all++
+			solved := solver.Grid.Clone()
+			solution.Apply(solved)
+			if c.keep(solved) {
+				expected[solution.Key()] = struct{}{}
+			}
+		}
+		if len(expected) == 0 || len(expected) == all {
+			t.Fatalf("%s: expected the input to remove some, but not all, of the %d solutions", c.name, all)
+		}
+
+		if err := c.setup(&solver); err != nil {
+			t.Fatal(err)
+		}
+		actual := make(map[string]struct{})
+		for solution := range c.solve(solver) {
+			actual[solution.Key()] = struct{}{}
+			if _, ok := expected[solution.Key()]; !ok {
+				t.Errorf("%s: solution does not follow the input: %v", c.name, solution)
+			}
+		}
+		if len(actual) != len(expected) {
+			t.Errorf("%s: expected %d solutions, got %d", c.name, len(expected), len(actual))
+		}
+	}
+}
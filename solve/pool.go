@@ -0,0 +1,209 @@
+package solve
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// workers returns the number of goroutines that g may use to solve independent branches.
+func (g GridSolver) workers() int {
+	if g.Workers > 0 {
+		return g.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// workPool runs tasks on a fixed number of goroutines. Each worker has its own deque of tasks,
+// which tasks are submitted to in turn. A worker runs the oldest task in its own deque, and once
+// its deque is empty, steals the newest task from another worker's deque.
+type workPool struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	deques [][]func()
+	next   int
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newWorkPool(workers int) *workPool {
+	p := &workPool{deques: make([][]func(), workers)}
+	p.cond = sync.NewCond(&p.mu)
+	p.wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go p.work(w)
+	}
+	return p
+}
+
+// submit adds task to the pool. It must not be called after close.
+func (p *workPool) submit(task func()) {
+	p.mu.Lock()
+	p.deques[p.next] = append(p.deques[p.next], task)
+	p.next = (p.next + 1) % len(p.deques)
+	p.mu.Unlock()
+	p.cond.Signal()
+}
+
+// close stops the pool once every submitted task has been run, and waits for its workers to exit.
+func (p *workPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+	p.wg.Wait()
+}
+
+func (p *workPool) work(w int) {
+	defer p.wg.Done()
+	for {
+		task, ok := p.take(w)
+		if !ok {
+			return
+		}
+		task()
+	}
+}
+
+// take returns the next task for worker w, or false once the pool is closed and there are no tasks left.
+func (p *workPool) take(w int) (func(), bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		if own := p.deques[w]; len(own) > 0 {
+			task := own[0]
+			own[0] = nil
+			p.deques[w] = own[1:]
+			return task, true
+		}
+		for i := 1; i < len(p.deques); i++ {
+			victim := (w + i) % len(p.deques)
+			if deque := p.deques[victim]; len(deque) > 0 {
+				task := deque[len(deque)-1]
+				deque[len(deque)-1] = nil
+				p.deques[victim] = deque[:len(deque)-1]
+				return task, true
+			}
+		}
+		if p.closed {
+			return nil, false
+		}
+		p.cond.Wait()
+	}
+}
+
+// runParallel calls f(i) for each i in [0, n) using workers goroutines, and waits for them to finish.
+func runParallel(workers, n int, f func(i int)) {
+	if workers <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	if workers > n {
+		workers = n
+	}
+
+	pool := newWorkPool(workers)
+	for i := 0; i < n; i++ {
+		i := i
+		pool.submit(func() { f(i) })
+	}
+	pool.close()
+}
+
+// parallelFlatMap is like flatMap, but the iterators returned by f are run on
+// g.workers() goroutines at once. Solutions are still returned in the same order.
+func (g GridSolver) parallelFlatMap(ctx context.Context, in SolutionIterator, f func(gs.Assignment) SolutionIterator) SolutionIterator {
+	workers := g.workers()
+	if workers <= 1 {
+		return flatMap(ctx, in, f)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	it := &parallelFlatMapIter{
+		ctx:     ctx,
+		cancel:  cancel,
+		pending: make(chan *parallelBranch, 2*workers),
+	}
+	it.wg.Add(1)
+	go it.dispatch(in, f, workers)
+	return it
+}
+
+// parallelFlatMapIter is the iterator returned by parallelFlatMap when it uses more than
+// one worker. Each solution from in becomes a branch, which is run by a workPool. Branches
+// are kept in pending in the order they were read, and returned in that order.
+type parallelFlatMapIter struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pending chan *parallelBranch
+	cur     *parallelBranch
+	wg      sync.WaitGroup
+}
+
+// parallelBranch is the solutions of the iterator returned by f for a single solution from in.
+type parallelBranch struct {
+	out chan gs.Assignment
+}
+
+// dispatch reads each solution from in and adds its branch to the pool. It only reads ahead
+// as far as there is room in pending, so that only a few branches are run ahead of Next.
+func (it *parallelFlatMapIter) dispatch(in SolutionIterator, f func(gs.Assignment) SolutionIterator, workers int) {
+	defer it.wg.Done()
+	defer close(it.pending)
+	defer in.Close()
+
+	pool := newWorkPool(workers)
+	defer pool.close()
+
+	for it.ctx.Err() == nil {
+		solution, ok := in.Next()
+		if !ok {
+			return
+		}
+
+		branch := &parallelBranch{out: make(chan gs.Assignment, 16)}
+		select {
+		case it.pending <- branch:
+		case <-it.ctx.Done():
+			return
+		}
+		pool.submit(func() {
+			defer close(branch.out)
+			sols := f(solution)
+			defer sols.Close()
+			for it.ctx.Err() == nil {
+				solution, ok := sols.Next()
+				if !ok || !sendSolution(it.ctx, branch.out, solution) {
+					return
+				}
+			}
+		})
+	}
+}
+
+func (it *parallelFlatMapIter) Next() (gs.Assignment, bool) {
+	for it.ctx.Err() == nil {
+		if it.cur == nil {
+			branch, ok := <-it.pending
+			if !ok {
+				return gs.Assignment{}, false
+			}
+			it.cur = branch
+		}
+		if solution, ok := <-it.cur.out; ok {
+			return solution, true
+		}
+		it.cur = nil
+	}
+	return gs.Assignment{}, false
+}
+
+// Close implements SolutionIterator. It waits for every goroutine used by it to exit.
+func (it *parallelFlatMapIter) Close() {
+	it.cancel()
+	it.wg.Wait()
+}
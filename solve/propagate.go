@@ -0,0 +1,174 @@
+package solve
+
+import (
+	"context"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// PropagationEngine is an Engine which keeps track of the colors that each tile may have.
+// Before guessing the color of a tile, it uses the same rules as Deductions to narrow
+// down the colors of the other tiles, which avoids guesses that cannot lead to a solution.
+//
+// Unknown tiles which cannot affect whether the level is solved keep their color, and are
+// not included in solutions.
+type PropagationEngine struct {
+	// OnError is called if the level cannot be solved by the engine, such as when it has more
+	// colors than a ColorSet can hold, before the channel of solutions is closed. If it is nil,
+	// errors are ignored.
+	OnError func(err error)
+}
+
+// SolveAll implements Engine.
+func (e PropagationEngine) SolveAll(ctx context.Context, g GridSolver) <-chan gs.Assignment {
+	ch := make(chan gs.Assignment)
+
+	go func() {
+		defer close(ch)
+
+		d, err := newDeducer(g)
+		if err != nil {
+			e.fail(err)
+			return
+		}
+
+		relevant := relevantTiles(g)
+		for x, col := range g.Grid.Tiles {
+			for y, tile := range col {
+				if tile.Data.Type != gs.TypeHole && !relevant.Has(tile.Coord) {
+					d.domains[x][y] = SingleColor(tile.Data.Color)
+				}
+			}
+		}
+
+		var unknown []gs.TileCoord
+		for x := 0; x < g.Grid.Width(); x++ {
+			for y := 0; y < g.Grid.Height(); y++ {
+				coord := gs.TileCoord{X: x, Y: y}
+				if relevant.Has(coord) && g.UnknownTiles.Has(coord) {
+					unknown = append(unknown, coord)
+				}
+			}
+		}
+
+		search := propagationSearch{ctx: ctx, solver: g, grid: g.Grid, unknown: unknown, ch: ch}
+		search.search(d)
+	}()
+
+	return ch
+}
+
+func (e PropagationEngine) fail(err error) {
+	if e.OnError != nil {
+		e.OnError(err)
+	}
+}
+
+type propagationSearch struct {
+	ctx     context.Context
+	solver  GridSolver
+	grid    gs.Grid
+	unknown []gs.TileCoord
+	ch      chan<- gs.Assignment
+}
+
+// search narrows down the colors in d, and then guesses the color of the unknown tile with
+// the fewest possible colors. It returns false if ctx was cancelled.
+func (s propagationSearch) search(d *deducer) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+	spendNodes(s.ctx, 1)
+	if err := d.propagate(); err != nil {
+		return true
+	}
+
+	guess, guessColors := -1, 0
+	for i, coord := range s.unknown {
+		if n := d.domain(coord).Len(); n > 1 && (guess < 0 || n < guessColors) {
+			guess, guessColors = i, n
+		}
+	}
+
+	if guess < 0 {
+		solved := s.grid.Clone()
+		var solution gs.Assignment
+		for _, coord := range s.unknown {
+			color, _ := d.color(coord)
+			solved.TileAtCoord(coord).Data.Color = color
+			solution.Set(coord, color)
+		}
+		if !solved.Valid() || !s.solver.followsPairs(solved) {
+			return true
+		}
+		return sendSolution(s.ctx, s.ch, solution)
+	}
+
+	coord := s.unknown[guess]
+	for _, color := range d.domain(coord).Colors() {
+		next := d.clone()
+		next.domains[coord.X][coord.Y] = SingleColor(color)
+		if !s.search(next) {
+			return false
+		}
+	}
+	return true
+}
+
+// relevantTiles returns the tiles whose colors can affect whether g is solved. These are
+// the neighbors of dots, tiles with forbidden colors, and every tile which is connected to
+// a goal, join, or custom tile. If g has any crowns, every tile is relevant.
+func relevantTiles(g GridSolver) gs.TileCoordSet {
+	var relevant gs.TileCoordSet
+	var stack []gs.TileCoord
+	for coord := range g.Forbidden {
+		relevant.Add(coord)
+	}
+
+	// tiles which are connected to each other in either direction
+	connected := make(map[gs.TileCoord][]gs.TileCoord)
+	var hasCrown bool
+	for _, col := range g.Grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type == gs.TypeHole {
+				continue
+			}
+			for _, neighbor := range g.Grid.NeighborSlice(tile.Coord) {
+				connected[tile.Coord] = append(connected[tile.Coord], neighbor.Coord)
+				connected[neighbor.Coord] = append(connected[neighbor.Coord], tile.Coord)
+			}
+
+			switch tile.Data.Type {
+			case gs.TypeBlank:
+			case gs.TypeCrown:
+				hasCrown = true
+			case gs.TypeDot1, gs.TypeDot2, gs.TypeDot3:
+				for _, neighbor := range g.Grid.NeighborSlice(tile.Coord) {
+					relevant.Add(neighbor.Coord)
+				}
+			default:
+				stack = append(stack, tile.Coord)
+			}
+		}
+	}
+
+	if hasCrown {
+		return g.Grid.TilesWith(func(o gs.Tile) bool { return true }).ToTileCoordSet()
+	}
+
+	// everything connected to a goal, join, or custom tile is relevant
+	visited := gs.NewTileCoordSet(stack...)
+	for len(stack) > 0 {
+		coord := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		relevant.Add(coord)
+		for _, next := range connected[coord] {
+			if !visited.Has(next) {
+				visited.Add(next)
+				stack = append(stack, next)
+			}
+		}
+	}
+
+	return relevant
+}
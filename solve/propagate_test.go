@@ -0,0 +1,108 @@
+package solve_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/example"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestPropagationEngine_levelF10(t *testing.T) {
+	const level = `
+	0    0    0    0    0    0
+	0    0e   0k   0    0    0
+	0    0    0k   0    0    0
+	0    0    0k   0    0    0
+	0    0    0k   0e   0    0
+	0j1  0e   0    0    0j1  0e
+	`
+	solutions := []string{
+		"111111|100001|111100|100010|101010|101110",
+		"000000|011110|000011|011101|010101|010001",
+	}
+
+	testSolveAbstract(t, level, solutions, 2, func(g solve.GridSolver) <-chan gs.Assignment {
+		g.Engine = solve.PropagationEngine{}
+		return g.SolveAllTiles()
+	})
+}
+
+// the propagation engine should find the same colorings as the default engine.
+func TestPropagationEngine_sameAsDefault(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Level     string
+		MaxColors int
+	}{
+		{"A2", example.LevelA2, 2},
+		{"A4", example.LevelA4, 2},
+		{"A5", example.LevelA5, 2},
+		{"dots", "0m2  0  0\n0  0  0m1", 2},
+		{"dots with colors", "0m2  0  0\n0  0  0m1", 3},
+		{"joins", "0j1  0  0  0j1", 3},
+		{"crowns", "0k  0  0\n0  0  0k", 2},
+		{"unsolvable", "0m3  0", 2},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			solver := solve.NewGridSolver(gs.MakeGridFromString(testCase.Level, testCase.MaxColors))
+			expected := solvedGrids(solver)
+
+			solver.Engine = solve.PropagationEngine{}
+			actual := solvedGrids(solver)
+
+			for grid := range expected {
+				if _, ok := actual[grid]; !ok {
+					t.Errorf("propagation engine did not find solution\n%v", grid)
+				}
+			}
+			for grid := range actual {
+				if _, ok := expected[grid]; !ok {
+					t.Errorf("propagation engine found incorrect solution\n%v", grid)
+				}
+			}
+		})
+	}
+}
+
+func TestPropagationEngine_cancel(t *testing.T) {
+	solver := solve.NewGridSolver(gs.MakeGridFromString("0j1  0  0  0  0  0  0  0  0j1", 4))
+	solver.Engine = solve.PropagationEngine{}
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, ok := <-solver.SolveAllTilesContext(ctx); !ok {
+		t.Fatalf("expected a solution")
+	}
+	cancel()
+
+	waitForGoroutines(t, before)
+}
+
+func TestPropagationEngine_tooManyColors(t *testing.T) {
+	var solverErr error
+	engine := solve.PropagationEngine{
+		OnError: func(err error) { solverErr = err },
+	}
+	for range engine.SolveAll(context.Background(), solve.NewGridSolver(gs.MakeGridFromString(example.LevelA1, 65))) {
+		t.Errorf("expected no solutions")
+	}
+	if solverErr == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+// solvedGrids returns the string of each distinct grid that g's solutions result in.
+func solvedGrids(g solve.GridSolver) map[string]struct{} {
+	grids := make(map[string]struct{})
+	for solution := range g.SolveAllTiles() {
+		solved := g.Grid.Clone()
+		solution.Apply(solved)
+		grids[solved.String()] = struct{}{}
+	}
+	return grids
+}
@@ -0,0 +1,11 @@
+package sat
+
+import "context"
+
+// SearchLevel runs a single search of s with at most maxConflicts conflicts, and returns
+// whether it ended in a restart, along with the decision level that it left s at.
+func (s *Solver) SearchLevel(maxConflicts int) (restarted bool, level int) {
+	s.maxLearnts = float64(len(s.clauses))/3 + 2000
+	status := s.search(context.Background(), maxConflicts, nil)
+	return status == searchRestart, s.decisionLevel()
+}
@@ -0,0 +1,73 @@
+package sat
+
+// varHeap is a max-heap of variables, ordered by their activity.
+type varHeap struct {
+	activity *[]float64
+	heap     []int
+	indices  []int // indices[v] is the position of v in heap, or -1 if it is not in the heap
+}
+
+func (h *varHeap) len() int {
+	return len(h.heap)
+}
+
+func (h *varHeap) contains(v int) bool {
+	return v < len(h.indices) && h.indices[v] >= 0
+}
+
+func (h *varHeap) less(i, j int) bool {
+	return (*h.activity)[h.heap[i]] > (*h.activity)[h.heap[j]]
+}
+
+func (h *varHeap) swap(i, j int) {
+	h.heap[i], h.heap[j] = h.heap[j], h.heap[i]
+	h.indices[h.heap[i]] = i
+	h.indices[h.heap[j]] = j
+}
+
+func (h *varHeap) insert(v int) {
+	for len(h.indices) <= v {
+		h.indices = append(h.indices, -1)
+	}
+	h.heap = append(h.heap, v)
+	h.indices[v] = len(h.heap) - 1
+	h.up(len(h.heap) - 1)
+}
+
+func (h *varHeap) removeMax() int {
+	v := h.heap[0]
+	last := len(h.heap) - 1
+	h.swap(0, last)
+	h.heap = h.heap[:last]
+	h.indices[v] = -1
+	h.down(0)
+	return v
+}
+
+func (h *varHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			return
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *varHeap) down(i int) {
+	for {
+		child := 2*i + 1
+		if child >= len(h.heap) {
+			return
+		}
+		if child+1 < len(h.heap) && h.less(child+1, child) {
+			child++
+		}
+		if !h.less(child, i) {
+			return
+		}
+		h.swap(i, child)
+		i = child
+	}
+}
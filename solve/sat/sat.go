@@ -0,0 +1,577 @@
+// Package sat is a conflict-driven clause learning SAT solver. It is used by the solve
+// package to solve levels which are encoded as CNF formulas, but can solve any formula.
+//
+// Variables are numbered starting at 1, and each literal is either a variable or its
+// negation, like in the DIMACS format.
+package sat
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// ErrDecisionLimit is returned by Solve when the solver makes more decisions than Solver.MaxDecisions.
+var ErrDecisionLimit = errors.New("sat: decision limit exceeded")
+
+// lit is a literal. Variable v (starting at 0) is represented as 2v, and its negation as 2v+1.
+type lit uint32
+
+const undefLit = ^lit(0)
+
+func fromDIMACS(l int) lit {
+	if l < 0 {
+		return lit(-l-1)<<1 | 1
+	}
+	return lit(l-1) << 1
+}
+
+func (l lit) toDIMACS() int {
+	if l&1 == 1 {
+		return -int(l>>1) - 1
+	}
+	return int(l>>1) + 1
+}
+
+func (l lit) v() int   { return int(l >> 1) }
+func (l lit) not() lit { return l ^ 1 }
+
+// values of variables and literals
+const (
+	valFalse int8 = -1
+	valUndef int8 = 0
+	valTrue  int8 = 1
+)
+
+type clause struct {
+	lits    []lit
+	learnt  bool
+	lbd     int
+	deleted bool
+}
+
+// Stats counts the work done by a Solver.
+type Stats struct {
+	Decisions    int64
+	Propagations int64
+	Conflicts    int64
+	Restarts     int64
+}
+
+// Solver is a SAT solver. Clauses can be added between calls to Solve, which makes it
+// possible to find every model of a formula by blocking each model after it is found.
+//
+// The zero value of Solver is an empty formula, which is satisfiable.
+type Solver struct {
+	// MaxDecisions is the most decisions the solver may make in total, across every call to
+	// Solve. If it is zero, there is no limit.
+	MaxDecisions int64
+
+	clauses []*clause
+	learnts []*clause
+	watches [][]*clause // watches[l] are the clauses watching l, which are visited when l becomes false
+
+	assigns  []int8
+	level    []int
+	reason   []*clause
+	polarity []bool // the last value of each variable, which is reused when deciding
+	seen     []bool
+
+	trail    []lit
+	trailLim []int
+	qhead    int
+
+	activity []float64
+	varInc   float64
+	order    varHeap
+
+	maxLearnts float64
+	unsat      bool
+	model      []int
+	stats      Stats
+}
+
+// NumVars returns the number of variables in the formula.
+func (s *Solver) NumVars() int {
+	return len(s.assigns)
+}
+
+// Stats returns the work done by s so far.
+func (s *Solver) Stats() Stats {
+	return s.stats
+}
+
+func (s *Solver) ensureVars(n int) {
+	if s.varInc == 0 {
+		s.varInc = 1
+		s.order.activity = &s.activity
+	}
+	for v := len(s.assigns); v < n; v++ {
+		s.assigns = append(s.assigns, valUndef)
+		s.level = append(s.level, 0)
+		s.reason = append(s.reason, nil)
+		s.polarity = append(s.polarity, false)
+		s.seen = append(s.seen, false)
+		s.activity = append(s.activity, 0)
+		s.watches = append(s.watches, nil, nil)
+		s.order.insert(v)
+	}
+}
+
+// AddClause adds a clause to the formula which is true if any of lits are true. It returns
+// false if the formula is now known to be unsatisfiable.
+func (s *Solver) AddClause(lits ...int) bool {
+	s.cancelUntil(0)
+	if s.unsat {
+		return false
+	}
+
+	clauseLits := make([]lit, 0, len(lits))
+	for _, l := range lits {
+		if l == 0 {
+			panic("sat: 0 is not a literal")
+		}
+		if l < 0 && -l > s.NumVars() {
+			s.ensureVars(-l)
+		} else if l > s.NumVars() {
+			s.ensureVars(l)
+		}
+		clauseLits = append(clauseLits, fromDIMACS(l))
+	}
+
+	// remove duplicate and false literals, and skip clauses which are already true
+	sort.Slice(clauseLits, func(i, j int) bool { return clauseLits[i] < clauseLits[j] })
+	j := 0
+	for i, l := range clauseLits {
+		switch {
+		case s.value(l) == valTrue, i > 0 && l == clauseLits[i-1].not():
+			return true
+		case s.value(l) == valFalse, i > 0 && l == clauseLits[i-1]:
+			continue
+		}
+		clauseLits[j] = l
+		j++
+	}
+	clauseLits = clauseLits[:j]
+
+	switch len(clauseLits) {
+	case 0:
+		s.unsat = true
+		return false
+	case 1:
+		s.enqueue(clauseLits[0], nil)
+		if s.propagate() != nil {
+			s.unsat = true
+			return false
+		}
+		return true
+	}
+
+	c := &clause{lits: clauseLits}
+	s.attach(c)
+	s.clauses = append(s.clauses, c)
+	return true
+}
+
+// Solve returns whether the formula is satisfiable when all of the assumptions are true.
+// If it is, the model can be retrieved with Model. If ctx is cancelled before the formula
+// is solved, ctx.Err() is returned, and if the solver makes too many decisions,
+// ErrDecisionLimit is returned.
+func (s *Solver) Solve(ctx context.Context, assumptions ...int) (bool, error) {
+	s.model = nil
+	s.cancelUntil(0)
+	if s.unsat {
+		return false, nil
+	}
+
+	assume := make([]lit, len(assumptions))
+	for i, l := range assumptions {
+		if l < 0 && -l > s.NumVars() {
+			s.ensureVars(-l)
+		} else if l > s.NumVars() {
+			s.ensureVars(l)
+		}
+		assume[i] = fromDIMACS(l)
+	}
+	if s.maxLearnts == 0 {
+		s.maxLearnts = float64(len(s.clauses))/3 + 2000
+	}
+
+	for restarts := 0; ; restarts++ {
+		status := s.search(ctx, 100*luby(restarts), assume)
+		switch status {
+		case searchSat:
+			s.model = make([]int, s.NumVars())
+			for v := range s.model {
+				if s.assigns[v] == valTrue {
+					s.model[v] = v + 1
+				} else {
+					s.model[v] = -(v + 1)
+				}
+			}
+			s.cancelUntil(0)
+			return true, nil
+		case searchUnsat:
+			s.cancelUntil(0)
+			return false, nil
+		case searchCancelled:
+			s.cancelUntil(0)
+			return false, ctx.Err()
+		case searchDecisionLimit:
+			s.cancelUntil(0)
+			return false, ErrDecisionLimit
+		}
+		s.stats.Restarts++
+	}
+}
+
+// Model returns the model found by the last call to Solve. It contains a literal for each
+// variable, which is the variable if it is true, or its negation if it is false.
+func (s *Solver) Model() []int {
+	return s.model
+}
+
+type searchStatus int
+
+const (
+	searchSat searchStatus = iota
+	searchUnsat
+	searchRestart
+	searchCancelled
+	searchDecisionLimit
+)
+
+// search makes decisions and learns from conflicts until the formula is solved, or until
+// maxConflicts conflicts have happened.
+func (s *Solver) search(ctx context.Context, maxConflicts int, assumptions []lit) searchStatus {
+	var conflicts int
+	for {
+		if confl := s.propagate(); confl != nil {
+			s.stats.Conflicts++
+			conflicts++
+			if s.decisionLevel() == 0 {
+				s.unsat = true
+				return searchUnsat
+			}
+
+			learnt, backtrackLevel, lbd := s.analyze(confl)
+			s.cancelUntil(backtrackLevel)
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], nil)
+			} else {
+				c := &clause{lits: learnt, learnt: true, lbd: lbd}
+				s.attach(c)
+				s.learnts = append(s.learnts, c)
+				s.enqueue(learnt[0], c)
+			}
+			s.varInc /= 0.95
+
+			if conflicts%256 == 0 && ctx.Err() != nil {
+				return searchCancelled
+			}
+			continue
+		}
+
+		if conflicts >= maxConflicts {
+			// restarting keeps the learnt clauses, but forgets every decision
+			s.cancelUntil(0)
+			return searchRestart
+		}
+		if float64(len(s.learnts)) >= s.maxLearnts {
+			s.reduceLearnts()
+		}
+
+		next := undefLit
+		for s.decisionLevel() < len(assumptions) {
+			p := assumptions[s.decisionLevel()]
+			if s.value(p) == valTrue {
+				s.newDecisionLevel()
+				continue
+			}
+			if s.value(p) == valFalse {
+				return searchUnsat
+			}
+			next = p
+			break
+		}
+
+		if next == undefLit {
+			if s.MaxDecisions > 0 && s.stats.Decisions >= s.MaxDecisions {
+				return searchDecisionLimit
+			}
+			s.stats.Decisions++
+			if s.stats.Decisions%1024 == 0 && ctx.Err() != nil {
+				return searchCancelled
+			}
+			next = s.pickBranchLit()
+			if next == undefLit {
+				return searchSat
+			}
+		}
+		s.newDecisionLevel()
+		s.enqueue(next, nil)
+	}
+}
+
+func (s *Solver) value(l lit) int8 {
+	val := s.assigns[l.v()]
+	if l&1 == 1 {
+		return -val
+	}
+	return val
+}
+
+func (s *Solver) decisionLevel() int {
+	return len(s.trailLim)
+}
+
+func (s *Solver) newDecisionLevel() {
+	s.trailLim = append(s.trailLim, len(s.trail))
+}
+
+func (s *Solver) enqueue(l lit, reason *clause) {
+	v := l.v()
+	if l&1 == 1 {
+		s.assigns[v] = valFalse
+	} else {
+		s.assigns[v] = valTrue
+	}
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = reason
+	s.trail = append(s.trail, l)
+}
+
+// cancelUntil undoes all assignments made above the given decision level.
+func (s *Solver) cancelUntil(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		v := s.trail[i].v()
+		s.polarity[v] = s.assigns[v] == valTrue
+		s.assigns[v] = valUndef
+		s.reason[v] = nil
+		if !s.order.contains(v) {
+			s.order.insert(v)
+		}
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+func (s *Solver) pickBranchLit() lit {
+	for s.order.len() > 0 {
+		v := s.order.removeMax()
+		if s.assigns[v] == valUndef {
+			if s.polarity[v] {
+				return lit(v) << 1
+			}
+			return lit(v)<<1 | 1
+		}
+	}
+	return undefLit
+}
+
+// attach watches the first two literals of c.
+func (s *Solver) attach(c *clause) {
+	s.watches[c.lits[0]] = append(s.watches[c.lits[0]], c)
+	s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+}
+
+// propagate assigns all literals which are implied by the current assignments. It returns
+// a clause whose literals are all false if there is a conflict.
+//
+// The first literal of a clause which implies an assignment is always the implied literal.
+func (s *Solver) propagate() *clause {
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead].not()
+		s.qhead++
+		s.stats.Propagations++
+
+		watchers := s.watches[falseLit]
+		i, j := 0, 0
+		for i < len(watchers) {
+			c := watchers[i]
+			i++
+			if c.deleted {
+				continue
+			}
+
+			if c.lits[0] == falseLit {
+				c.lits[0], c.lits[1] = c.lits[1], c.lits[0]
+			}
+			if s.value(c.lits[0]) == valTrue {
+				watchers[j] = c
+				j++
+				continue
+			}
+
+			// look for a new literal to watch
+			moved := false
+			for k := 2; k < len(c.lits); k++ {
+				if s.value(c.lits[k]) != valFalse {
+					c.lits[1], c.lits[k] = c.lits[k], c.lits[1]
+					s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			watchers[j] = c
+			j++
+			if s.value(c.lits[0]) == valFalse {
+				for i < len(watchers) {
+					watchers[j] = watchers[i]
+					i++
+					j++
+				}
+				s.watches[falseLit] = watchers[:j]
+				s.qhead = len(s.trail)
+				return c
+			}
+			s.enqueue(c.lits[0], c)
+		}
+		s.watches[falseLit] = watchers[:j]
+	}
+	return nil
+}
+
+// analyze finds the first unique implication point of a conflict, and returns the clause
+// which should be learned from it, the level to backtrack to, and the number of distinct
+// decision levels in the clause.
+func (s *Solver) analyze(confl *clause) (learnt []lit, backtrackLevel int, lbd int) {
+	learnt = []lit{undefLit}
+	pathCount := 0
+	p := undefLit
+	index := len(s.trail) - 1
+
+	for {
+		start := 0
+		if p != undefLit {
+			start = 1
+		}
+		for _, q := range confl.lits[start:] {
+			v := q.v()
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.bumpVar(v)
+			s.seen[v] = true
+			if s.level[v] >= s.decisionLevel() {
+				pathCount++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+
+		for !s.seen[s.trail[index].v()] {
+			index--
+		}
+		p = s.trail[index]
+		index--
+		confl = s.reason[p.v()]
+		s.seen[p.v()] = false
+		pathCount--
+		if pathCount == 0 {
+			break
+		}
+	}
+	learnt[0] = p.not()
+
+	// remove literals which are implied by the other literals in the clause
+	toClear := append([]lit(nil), learnt[1:]...)
+	j := 1
+	for _, q := range learnt[1:] {
+		if !s.impliedBySeen(s.reason[q.v()]) {
+			learnt[j] = q
+			j++
+		}
+	}
+	learnt = learnt[:j]
+	for _, q := range toClear {
+		s.seen[q.v()] = false
+	}
+
+	// the literal with the highest level is watched along with the asserting literal
+	if len(learnt) > 1 {
+		highest := 1
+		for i := 2; i < len(learnt); i++ {
+			if s.level[learnt[i].v()] > s.level[learnt[highest].v()] {
+				highest = i
+			}
+		}
+		learnt[1], learnt[highest] = learnt[highest], learnt[1]
+		backtrackLevel = s.level[learnt[1].v()]
+	}
+
+	levels := make(map[int]struct{}, len(learnt))
+	for _, q := range learnt {
+		levels[s.level[q.v()]] = struct{}{}
+	}
+	return learnt, backtrackLevel, len(levels)
+}
+
+// impliedBySeen returns if every literal in reason other than the first is in the learnt clause.
+func (s *Solver) impliedBySeen(reason *clause) bool {
+	if reason == nil {
+		return false
+	}
+	for _, q := range reason.lits[1:] {
+		if !s.seen[q.v()] && s.level[q.v()] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Solver) bumpVar(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.varInc *= 1e-100
+	}
+	if s.order.contains(v) {
+		s.order.up(s.order.indices[v])
+	}
+}
+
+// reduceLearnts removes half of the learnt clauses, keeping the ones with the fewest distinct
+// decision levels, since they are the most likely to be useful.
+func (s *Solver) reduceLearnts() {
+	sort.SliceStable(s.learnts, func(i, j int) bool {
+		return s.learnts[i].lbd > s.learnts[j].lbd
+	})
+
+	kept := s.learnts[:0]
+	for i, c := range s.learnts {
+		locked := s.reason[c.lits[0].v()] == c && s.value(c.lits[0]) == valTrue
+		if i < len(s.learnts)/2 && c.lbd > 2 && !locked {
+			c.deleted = true
+			continue
+		}
+		kept = append(kept, c)
+	}
+	s.learnts = kept
+	s.maxLearnts *= 1.1
+}
+
+// luby returns the i'th element of the Luby sequence, 1 1 2 1 1 2 4 1 1 2 ...
+func luby(i int) int {
+	size, exp := 1, 0
+	for size < i+1 {
+		exp++
+		size = 2*size + 1
+	}
+	for size-1 != i {
+		size = (size - 1) / 2
+		exp--
+		i %= size
+	}
+	return 1 << exp
+}
@@ -0,0 +1,204 @@
+package sat_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/deanveloper/gridspech-go/solve/sat"
+)
+
+func TestSolver_simple(t *testing.T) {
+	var s sat.Solver
+	s.AddClause(1, 2)
+	s.AddClause(-1, 2)
+	s.AddClause(-2, 3)
+
+	ok, err := s.Solve(context.Background())
+	if err != nil || !ok {
+		t.Fatalf("expected satisfiable, got %v, %v", ok, err)
+	}
+	model := s.Model()
+	if model[1] != 2 || model[2] != 3 {
+		t.Errorf("expected 2 and 3 to be true, got %v", model)
+	}
+
+	s.AddClause(-3)
+	if ok, _ := s.Solve(context.Background()); ok {
+		t.Errorf("expected unsatisfiable, got %v", s.Model())
+	}
+}
+
+func TestSolver_empty(t *testing.T) {
+	var s sat.Solver
+	if ok, err := s.Solve(context.Background()); !ok || err != nil {
+		t.Errorf("expected empty formula to be satisfiable, got %v, %v", ok, err)
+	}
+	if s.AddClause() {
+		t.Errorf("expected empty clause to make formula unsatisfiable")
+	}
+	if ok, _ := s.Solve(context.Background()); ok {
+		t.Errorf("expected unsatisfiable")
+	}
+}
+
+// pigeonhole returns a formula which places n+1 pigeons into n holes, which is unsatisfiable.
+func pigeonhole(n int) *sat.Solver {
+	var s sat.Solver
+	v := func(pigeon, hole int) int { return pigeon*n + hole + 1 }
+	for p := 0; p <= n; p++ {
+		var clause []int
+		for h := 0; h < n; h++ {
+			clause = append(clause, v(p, h))
+		}
+		s.AddClause(clause...)
+	}
+	for h := 0; h < n; h++ {
+		for p1 := 0; p1 <= n; p1++ {
+			for p2 := p1 + 1; p2 <= n; p2++ {
+				s.AddClause(-v(p1, h), -v(p2, h))
+			}
+		}
+	}
+	return &s
+}
+
+func TestSolver_pigeonhole(t *testing.T) {
+	s := pigeonhole(7)
+	ok, err := s.Solve(context.Background())
+	if err != nil || ok {
+		t.Errorf("expected unsatisfiable, got %v, %v", ok, err)
+	}
+	if s.Stats().Conflicts == 0 {
+		t.Errorf("expected conflicts to be counted")
+	}
+}
+
+func TestSolver_restart(t *testing.T) {
+	s := pigeonhole(7)
+	restarted, level := s.SearchLevel(10)
+	if !restarted {
+		t.Fatalf("expected the search to restart")
+	}
+	if level != 0 {
+		t.Errorf("expected a restart to backtrack to decision level 0, got %d", level)
+	}
+
+	if ok, err := s.Solve(context.Background()); ok || err != nil {
+		t.Errorf("expected unsatisfiable after a restart, got %v, %v", ok, err)
+	}
+}
+
+func TestSolver_cancel(t *testing.T) {
+	s := pigeonhole(12)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := s.Solve(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestSolver_maxDecisions(t *testing.T) {
+	s := pigeonhole(12)
+	s.MaxDecisions = 100
+
+	if _, err := s.Solve(context.Background()); err != sat.ErrDecisionLimit {
+		t.Errorf("expected decision limit to be exceeded, got %v", err)
+	}
+	if decisions := s.Stats().Decisions; decisions != 100 {
+		t.Errorf("expected exactly 100 decisions, got %d", decisions)
+	}
+}
+
+func TestSolver_assumptions(t *testing.T) {
+	var s sat.Solver
+	s.AddClause(-1, 2)
+	s.AddClause(-2, 3)
+
+	if ok, _ := s.Solve(context.Background(), 1, -3); ok {
+		t.Errorf("expected unsatisfiable with assumptions, got %v", s.Model())
+	}
+	ok, _ := s.Solve(context.Background(), 1)
+	if !ok || s.Model()[2] != 3 {
+		t.Errorf("expected 3 to be implied by assumption, got %v", s.Model())
+	}
+	if ok, _ := s.Solve(context.Background()); !ok {
+		t.Errorf("expected assumptions to not be kept")
+	}
+}
+
+// the number of models found by blocking each one should match a brute force count,
+// and each model should satisfy the formula.
+func TestSolver_random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		numVars := 3 + r.Intn(8)
+		var clauses [][]int
+		for i := 0; i < numVars*3; i++ {
+			var clause []int
+			for j := 0; j < 1+r.Intn(3); j++ {
+				l := 1 + r.Intn(numVars)
+				if r.Intn(2) == 0 {
+					l = -l
+				}
+				clause = append(clause, l)
+			}
+			clauses = append(clauses, clause)
+		}
+
+		var expected int
+		for mask := 0; mask < 1<<numVars; mask++ {
+			if satisfies(clauses, func(v int) bool { return mask>>(v-1)&1 == 1 }) {
+				expected++
+			}
+		}
+
+		var s sat.Solver
+		for _, clause := range clauses {
+			s.AddClause(clause...)
+		}
+		s.AddClause(numVars, -numVars) // make sure every variable exists
+
+		var actual int
+		for {
+			ok, err := s.Solve(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				break
+			}
+			actual++
+			model := s.Model()
+			if !satisfies(clauses, func(v int) bool { return model[v-1] > 0 }) {
+				t.Fatalf("model %v does not satisfy %v", model, clauses)
+			}
+			blocking := make([]int, len(model))
+			for i, l := range model {
+				blocking[i] = -l
+			}
+			s.AddClause(blocking...)
+		}
+		if actual != expected {
+			t.Fatalf("expected %d models, got %d for %v", expected, actual, clauses)
+		}
+	}
+}
+
+func satisfies(clauses [][]int, value func(v int) bool) bool {
+	for _, clause := range clauses {
+		satisfied := false
+		for _, l := range clause {
+			if l > 0 && value(l) || l < 0 && !value(-l) {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package solve
+
+import (
+	"context"
+	"fmt"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve/sat"
+)
+
+// SATEngine is an Engine which encodes the level with EncodeCNF, and finds solutions with
+// the SAT solver in the sat package. Each solution is blocked after it is found, so that the
+// solver finds a different one next.
+type SATEngine struct {
+	// OnError is called if the level cannot be encoded, before the channel of
+	// solutions is closed. If it is nil, errors are ignored.
+	OnError func(err error)
+}
+
+// SolveAll implements Engine. Like PropagationEngine, unknown tiles which cannot affect
+// whether the level is solved keep their color, and are not included in solutions.
+func (e SATEngine) SolveAll(ctx context.Context, g GridSolver) <-chan gs.Assignment {
+	ch := make(chan gs.Assignment)
+
+	go func() {
+		defer close(ch)
+
+		relevant := relevantTiles(g)
+		g = g.Clone()
+		g.UnknownTiles.RemoveIf(func(coord gs.TileCoord) bool {
+			return !relevant.Has(coord)
+		})
+
+		enc, err := g.EncodeCNF()
+		if err != nil {
+			e.fail(err)
+			return
+		}
+		var solver sat.Solver
+		for _, clause := range enc.Clauses {
+			solver.AddClause(clause...)
+		}
+
+		for {
+			if remaining, ok := remainingNodes(ctx); ok {
+				if remaining == 0 {
+					spendNodes(ctx, 1)
+					return
+				}
+				solver.MaxDecisions = solver.Stats().Decisions + remaining
+			}
+			decisions := solver.Stats().Decisions
+			ok, err := solver.Solve(ctx)
+			spendNodes(ctx, solver.Stats().Decisions-decisions)
+			if err == sat.ErrDecisionLimit {
+				spendNodes(ctx, 1)
+			}
+			if err != nil || !ok {
+				return
+			}
+			solution, err := enc.Decode(solver.Model())
+			if err != nil {
+				e.fail(err)
+				return
+			}
+
+			solved := g.Grid.Clone()
+			solution.Apply(solved)
+			if !solved.Valid() {
+				e.fail(fmt.Errorf("solver found invalid solution %v", solution))
+				return
+			}
+			if !sendSolution(ctx, ch, solution) {
+				return
+			}
+			solver.AddClause(enc.BlockingClause(solution)...)
+		}
+	}()
+
+	return ch
+}
+
+func (e SATEngine) fail(err error) {
+	if e.OnError != nil {
+		e.OnError(err)
+	}
+}
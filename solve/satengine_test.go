@@ -0,0 +1,75 @@
+package solve_test
+
+import (
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/example"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestSATEngine_levelF10(t *testing.T) {
+	const level = `
+	0    0    0    0    0    0
+	0    0e   0k   0    0    0
+	0    0    0k   0    0    0
+	0    0    0k   0    0    0
+	0    0    0k   0e   0    0
+	0j1  0e   0    0    0j1  0e
+	`
+	solutions := []string{
+		"111111|100001|111100|100010|101010|101110",
+		"000000|011110|000011|011101|010101|010001",
+	}
+
+	testSolveAbstract(t, level, solutions, 2, func(g solve.GridSolver) <-chan gs.Assignment {
+		g.Engine = solve.SATEngine{}
+		return g.SolveAllTiles()
+	})
+}
+
+// this level is too big for the default engine to solve.
+func TestSATEngine_levelE8(t *testing.T) {
+	const level = `
+	0m2  0m2  0m2  0m2  0m2  0m2  0m2
+	0m2  0m2  0m3  0m2  0m2  0m2  0m2
+	0m2  0m2  0m2  0m2  0m2  0m2  0m2
+	0m2  0m2  0m2  _    0m2  0m2  0m2
+	0m2  0m2  0m2  0m2  0m2  0m3  0m2
+	0m2  0m2  0m2  0m2  0m2  0m2  0m2
+	0m2  0m2  0m2  0m2  0m2  0m2  0m2
+	`
+	solutions := []string{
+		"1111011|1001011|1011000|101 111|1011101|1000001|1111111",
+	}
+
+	testSolveAbstract(t, level, solutions, 2, func(g solve.GridSolver) <-chan gs.Assignment {
+		g.Engine = solve.SATEngine{}
+		return g.SolveAllTiles()
+	})
+}
+
+func TestSATEngine_sameAsDefault(t *testing.T) {
+	levels := []string{example.LevelA2, example.LevelA5, "0m2  0  0\n0  0  0m1", "0k  0  0\n0  0  0k", "0m3  0"}
+
+	for _, level := range levels {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+		expected := solvedGrids(solver)
+
+		var engineErr error
+		solver.Engine = solve.SATEngine{OnError: func(err error) { engineErr = err }}
+		actual := solvedGrids(solver)
+		if engineErr != nil {
+			t.Fatal(engineErr)
+		}
+
+		if len(actual) != len(expected) {
+			t.Errorf("expected %d solutions, got %d for level\n%s", len(expected), len(actual), level)
+		}
+		for grid := range actual {
+			if _, ok := expected[grid]; !ok {
+				t.Errorf("incorrect solution\n%v", grid)
+			}
+		}
+	}
+}
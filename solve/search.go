@@ -0,0 +1,188 @@
+package solve
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// SolveOptions limits how much work a Search may do.
+type SolveOptions struct {
+	// Limit is the most solutions which will be returned. If it is less than 1, there is no limit.
+	Limit int
+
+	// MaxNodes is the most search nodes which may be explored. A node is a single step of the
+	// search, such as extending a path or shape, trying a permutation of a dot's neighbors,
+	// making a decision in the SAT solver, or running an external SAT solver. If it is less
+	// than 1, there is no limit.
+	MaxNodes int64
+
+	// Timeout is how long the search may run for. If it is 0, there is no limit.
+	Timeout time.Duration
+}
+
+// SolveStatus is the reason that a Search stopped.
+type SolveStatus int
+
+const (
+	// StatusExhausted means that every solution was found.
+	StatusExhausted SolveStatus = iota
+	// StatusLimitReached means that SolveOptions.Limit solutions were found.
+	StatusLimitReached
+	// StatusBudgetExceeded means that the search ran out of nodes or time.
+	StatusBudgetExceeded
+	// StatusCancelled means that the context passed to the Search was cancelled.
+	StatusCancelled
+)
+
+func (s SolveStatus) String() string {
+	switch s {
+	case StatusExhausted:
+		return "exhausted"
+	case StatusLimitReached:
+		return "limit reached"
+	case StatusBudgetExceeded:
+		return "budget exceeded"
+	case StatusCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// Search is a SolutionIterator which stops once it runs into any of the limits in its SolveOptions.
+// Once Next returns false, Status reports why the search stopped.
+type Search struct {
+	it     SolutionIterator
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	budget *nodeBudget
+
+	limit  int
+	found  int
+	done   bool
+	status SolveStatus
+}
+
+// Search returns a Search over the solutions of all tiles in g, like IterateAllTiles.
+func (g GridSolver) Search(ctx context.Context, opts SolveOptions) *Search {
+	return NewSearch(ctx, opts, g.IterateAllTiles)
+}
+
+// NewSearch returns a Search over the iterator returned by f. The context passed to f is
+// cancelled once the search's budget is exceeded, and is used to count the nodes explored.
+func NewSearch(ctx context.Context, opts SolveOptions, f func(ctx context.Context) SolutionIterator) *Search {
+	s := &Search{parent: ctx, limit: opts.Limit}
+	if opts.Timeout > 0 {
+		s.ctx, s.cancel = context.WithTimeout(ctx, opts.Timeout)
+	} else {
+		s.ctx, s.cancel = context.WithCancel(ctx)
+	}
+	s.budget = &nodeBudget{max: opts.MaxNodes, cancel: s.cancel}
+	s.ctx = context.WithValue(s.ctx, nodeBudgetKey{}, s.budget)
+	s.it = f(s.ctx)
+	return s
+}
+
+// Next implements SolutionIterator.
+func (s *Search) Next() (gs.Assignment, bool) {
+	if s.done {
+		return gs.Assignment{}, false
+	}
+	if s.limit > 0 && s.found >= s.limit {
+		s.finish(StatusLimitReached)
+		return gs.Assignment{}, false
+	}
+
+	solution, ok := s.it.Next()
+	if !ok {
+		switch {
+		case s.budget.isExceeded():
+			s.finish(StatusBudgetExceeded)
+		case s.parent.Err() != nil:
+			s.finish(StatusCancelled)
+		case s.ctx.Err() != nil:
+			s.finish(StatusBudgetExceeded)
+		default:
+			s.finish(StatusExhausted)
+		}
+		return gs.Assignment{}, false
+	}
+	s.found++
+	return solution, true
+}
+
+// Close implements SolutionIterator. If the search had not stopped yet, its status
+// becomes StatusCancelled.
+func (s *Search) Close() {
+	s.finish(StatusCancelled)
+}
+
+func (s *Search) finish(status SolveStatus) {
+	if s.done {
+		return
+	}
+	s.done, s.status = true, status
+	s.it.Close()
+	s.cancel()
+}
+
+// Status returns why the search stopped. It is only meaningful after Next has returned false.
+func (s *Search) Status() SolveStatus {
+	return s.status
+}
+
+// Found returns the number of solutions returned by Next.
+func (s *Search) Found() int {
+	return s.found
+}
+
+// Nodes returns the number of search nodes explored so far.
+func (s *Search) Nodes() int64 {
+	return atomic.LoadInt64(&s.budget.nodes)
+}
+
+// nodeBudgetKey is the context key of the *nodeBudget used by spendNodes.
+type nodeBudgetKey struct{}
+
+// nodeBudget counts the nodes explored by a Search, and cancels it once there are too many.
+type nodeBudget struct {
+	nodes    int64 // accessed atomically
+	exceeded int32 // accessed atomically
+	max      int64
+	cancel   context.CancelFunc
+}
+
+// spendNodes records that n search nodes were explored by the Search which ctx belongs to,
+// if any. If its budget is exceeded, ctx is cancelled.
+func spendNodes(ctx context.Context, n int64) {
+	b, ok := ctx.Value(nodeBudgetKey{}).(*nodeBudget)
+	if !ok {
+		return
+	}
+	if nodes := atomic.AddInt64(&b.nodes, n); b.max > 0 && nodes > b.max {
+		atomic.StoreInt32(&b.exceeded, 1)
+		b.cancel()
+	}
+}
+
+// remainingNodes returns how many more nodes the Search which ctx belongs to may explore,
+// or false if there is no limit.
+func remainingNodes(ctx context.Context) (int64, bool) {
+	b, ok := ctx.Value(nodeBudgetKey{}).(*nodeBudget)
+	if !ok || b.max < 1 {
+		return 0, false
+	}
+	remaining := b.max - atomic.LoadInt64(&b.nodes)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+func (b *nodeBudget) isExceeded() bool {
+	return atomic.LoadInt32(&b.exceeded) == 1
+}
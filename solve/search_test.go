@@ -0,0 +1,63 @@
+package solve_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestSearch(t *testing.T) {
+	const level = `
+	0e  0  0
+	0   0  0e
+	`
+	const numSolutions = 6
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	satSolver := solver.Clone()
+	satSolver.Engine = solve.SATEngine{}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		opts   solve.SolveOptions
+		status solve.SolveStatus
+	}{
+		{"none", context.Background(), solve.SolveOptions{}, solve.StatusExhausted},
+		{"limit", context.Background(), solve.SolveOptions{Limit: 2}, solve.StatusLimitReached},
+		{"high limit", context.Background(), solve.SolveOptions{Limit: 100}, solve.StatusExhausted},
+		{"max nodes", context.Background(), solve.SolveOptions{MaxNodes: 3}, solve.StatusBudgetExceeded},
+		{"timeout", context.Background(), solve.SolveOptions{Timeout: time.Nanosecond}, solve.StatusBudgetExceeded},
+		{"cancelled", cancelled, solve.SolveOptions{}, solve.StatusCancelled},
+	}
+
+	for _, s := range []solve.GridSolver{solver, satSolver} {
+		for _, test := range tests {
+			search := s.Search(test.ctx, test.opts)
+			found := len(solve.CollectSolutions(search))
+
+			if search.Status() != test.status {
+				t.Errorf("%s: expected status %v, got %v", test.name, test.status, search.Status())
+			}
+			if found != search.Found() {
+				t.Errorf("%s: expected Found to be %d, got %d", test.name, found, search.Found())
+			}
+			switch {
+			case test.status == solve.StatusExhausted && found != numSolutions:
+				t.Errorf("%s: expected %d solutions, got %d", test.name, numSolutions, found)
+			case test.status == solve.StatusLimitReached && found != test.opts.Limit:
+				t.Errorf("%s: expected %d solutions, got %d", test.name, test.opts.Limit, found)
+			case test.status == solve.StatusBudgetExceeded && found >= numSolutions:
+				t.Errorf("%s: expected the search to stop early, got %d solutions", test.name, found)
+			}
+			if test.opts.MaxNodes > 0 && search.Nodes() <= test.opts.MaxNodes {
+				t.Errorf("%s: expected more than %d nodes to be explored, got %d", test.name, test.opts.MaxNodes, search.Nodes())
+			}
+		}
+	}
+}
@@ -12,11 +12,11 @@ func testShapesIterAbstract(t *testing.T, level string, start gs.TileCoord, colo
 
 	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
 
-	var expectedSolutions []gs.TileSet
-	var actualSolutions []gs.TileSet
+	var expectedSolutions []gs.Assignment
+	var actualSolutions []gs.Assignment
 
 	for _, solutionString := range expectedSolutionsStrings {
-		expectedSolutions = append(expectedSolutions, tileSetFromString(solver.Grid, solutionString))
+		expectedSolutions = append(expectedSolutions, assignmentFromString(solver.Grid, solutionString))
 	}
 
 	solutionsCh, prune := solver.ShapesIter(start, color)
@@ -25,7 +25,7 @@ func testShapesIterAbstract(t *testing.T, level string, start gs.TileCoord, colo
 		prune <- false
 	}
 
-	testUnorderedTilesetSliceEq(t, expectedSolutions, actualSolutions)
+	testUnorderedAssignmentSliceEq(t, expectedSolutions, actualSolutions)
 }
 
 func TestShapesIter_small(t *testing.T) {
@@ -103,7 +103,7 @@ func TestShapesIter_noDuplicates(t *testing.T) {
 	`
 	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
 
-	var solutions []gs.TileSet
+	var solutions []gs.Assignment
 
 	solutionsCh, pruneCh := solver.ShapesIter(gs.TileCoord{X: 1, Y: 1}, 1)
 	for sol := range solutionsCh {
@@ -2,6 +2,7 @@ package solve
 
 import (
 	"container/heap"
+	"context"
 
 	gs "github.com/deanveloper/gridspech-go"
 )
@@ -36,90 +37,147 @@ func (e blobHeap) Len() int {
 
 // ShapesIter returns an iterator of all shapes which contain `start`, and be made out `color`, as well
 // as a communication channel to say whether we should prune the set here or not.
-// Both returned channels are closed by this function.
-func (g GridSolver) ShapesIter(start gs.TileCoord, color gs.TileColor) (<-chan gs.TileSet, chan<- bool) {
-	solutionsChan := make(chan gs.TileSet)
+// After receiving each shape, a value must be sent to the prune channel.
+// The solutions channel is closed by this function.
+func (g GridSolver) ShapesIter(start gs.TileCoord, color gs.TileColor) (<-chan gs.Assignment, chan<- bool) {
+	return g.ShapesIterContext(context.Background(), start, color)
+}
+
+// ShapesIterContext is like ShapesIter, but stops once ctx is cancelled. Consumers should
+// also stop waiting to send to the prune channel once ctx is cancelled.
+func (g GridSolver) ShapesIterContext(ctx context.Context, start gs.TileCoord, color gs.TileColor) (<-chan gs.Assignment, chan<- bool) {
+	solutionsChan := make(chan gs.Assignment)
 	pruneChan := make(chan bool)
 
 	go func() {
 		defer close(solutionsChan)
-		defer close(pruneChan)
 
-		if !g.UnknownTiles.Has(start) && g.Grid.TileAtCoord(start).Data.Color != color {
-			return
+		shapes := g.IterateShapes(ctx, start, color)
+		defer shapes.Close()
+		for {
+			shape, ok := shapes.Next()
+			if !ok || !sendSolution(ctx, solutionsChan, shape) {
+				return
+			}
+			select {
+			case prune := <-pruneChan:
+				if prune {
+					shapes.Prune()
+				}
+			case <-ctx.Done():
+				return
+			}
 		}
-
-		g.bfsShapes(start, color, solutionsChan, pruneChan)
 	}()
 
 	return solutionsChan, pruneChan
 }
 
-func (g GridSolver) bfsShapes(start gs.TileCoord, color gs.TileColor, solutions chan<- gs.TileSet, pruneChan <-chan bool) {
+// IterateShapes is like ShapesIterContext, but returns an iterator. Instead of sending
+// to a prune channel, Prune may be called after Next to prune the shape it returned.
+func (g GridSolver) IterateShapes(ctx context.Context, start gs.TileCoord, color gs.TileColor) *ShapeIterator {
+	it := &ShapeIterator{ctx: ctx, g: g, color: color, blobSize: 1}
+	if !g.UnknownTiles.Has(start) && g.Grid.TileAtCoord(start).Data.Color != color {
+		return it
+	}
 
 	initialBlob := g.Grid.BlobWith(start, func(o gs.Tile) bool {
 		return o.Data.Color == color && !g.UnknownTiles.Has(o.Coord)
 	}).ToTileCoordSet()
 	initialBlob.Add(start)
 
-	var blobPQ blobHeap
-	heap.Init(&blobPQ)
-	heap.Push(&blobPQ, initialBlob)
+	heap.Init(&it.blobPQ)
+	heap.Push(&it.blobPQ, initialBlob)
+	return it
+}
 
-	var dupeChecker []gs.TileCoordSet
-	blobSize := 1
+// ShapeIterator is the SolutionIterator returned by IterateShapes. Shapes are
+// returned smallest first, and each shape is grown from shapes which were not pruned.
+type ShapeIterator struct {
+	ctx   context.Context
+	g     GridSolver
+	color gs.TileColor
 
-	for blobPQ.Len() > 0 {
+	blobPQ      blobHeap
+	dupeChecker []gs.TileCoordSet
+	blobSize    int
 
-		curShape := heap.Pop(&blobPQ).(gs.TileCoordSet)
+	last   gs.TileCoordSet
+	grow   bool
+	closed bool
+}
 
-		if curShape.Len() > blobSize {
-			dupeChecker = nil
-			blobSize = curShape.Len()
-		}
+// Next implements SolutionIterator.
+func (it *ShapeIterator) Next() (gs.Assignment, bool) {
+	if it.grow {
+		it.growShape(it.last)
+		it.grow = false
+	}
+	if it.closed || it.blobPQ.Len() == 0 || it.ctx.Err() != nil {
+		return gs.Assignment{}, false
+	}
 
-		tileSet := curShape.ToTileSet(func(t gs.TileCoord) gs.Tile {
-			tileCopy := *g.Grid.TileAtCoord(t)
-			tileCopy.Data.Color = color
-			return tileCopy
-		})
-		solutions <- tileSet
-		if <-pruneChan {
-			continue
-		}
+	curShape := heap.Pop(&it.blobPQ).(gs.TileCoordSet)
+	spendNodes(it.ctx, 1)
 
-		nextNeighbors := g.aroundShape(curShape, func(o gs.Tile) bool {
-			return g.UnknownTiles.Has(o.Coord) || o.Data.Color == color
-		})
+	if curShape.Len() > it.blobSize {
+		it.dupeChecker = nil
+		it.blobSize = curShape.Len()
+	}
 
-	neighborLoop:
-		for _, nextNeighbor := range nextNeighbors.Slice() {
-			var newShape gs.TileCoordSet
-			newShape.Merge(curShape)
-			newShape.Add(nextNeighbor)
+	it.last, it.grow = curShape, true
+	it.g.observe(EventShapeGenerated)
+	return assignColor(curShape, it.color), true
+}
+
+// Prune stops the shape last returned by Next from being grown into larger shapes.
+func (it *ShapeIterator) Prune() {
+	if it.grow {
+		it.g.observe(EventShapePruned)
+	}
+	it.grow = false
+}
+
+// Close implements SolutionIterator.
+func (it *ShapeIterator) Close() {
+	it.closed, it.grow = true, false
+	it.blobPQ, it.dupeChecker = nil, nil
+}
+
+func (it *ShapeIterator) growShape(curShape gs.TileCoordSet) {
+	g, color := it.g, it.color
+
+	nextNeighbors := g.aroundShape(curShape, func(o gs.Tile) bool {
+		return g.UnknownTiles.Has(o.Coord) || o.Data.Color == color
+	})
 
-			// special behavior: if nextNeighbor has any neighbors which we know are the same color,
-			// add the blob of each of those neighbors to newShape
-			nextNextNeighbors := g.Grid.NeighborSetWith(nextNeighbor, func(o gs.Tile) bool {
-				return o.Data.Color == color && !g.UnknownTiles.Has(o.Coord) && !newShape.Has(o.Coord)
+neighborLoop:
+	for _, nextNeighbor := range nextNeighbors.Slice() {
+		var newShape gs.TileCoordSet
+		newShape.Merge(curShape)
+		newShape.Add(nextNeighbor)
+
+		// special behavior: if nextNeighbor has any neighbors which we know are the same color,
+		// add the blob of each of those neighbors to newShape
+		nextNextNeighbors := g.Grid.NeighborSetWith(nextNeighbor, func(o gs.Tile) bool {
+			return o.Data.Color == color && !g.UnknownTiles.Has(o.Coord) && !newShape.Has(o.Coord)
+		})
+		for _, transitiveNeighbor := range nextNextNeighbors.Slice() {
+			neighborBlob := g.Grid.BlobWith(transitiveNeighbor.Coord, func(o gs.Tile) bool {
+				return !g.UnknownTiles.Has(o.Coord)
 			})
-			for _, transitiveNeighbor := range nextNextNeighbors.Slice() {
-				neighborBlob := g.Grid.BlobWith(transitiveNeighbor.Coord, func(o gs.Tile) bool {
-					return !g.UnknownTiles.Has(o.Coord)
-				})
-				newShape.Merge(neighborBlob.ToTileCoordSet())
-			}
+			newShape.Merge(neighborBlob.ToTileCoordSet())
+		}
 
-			// check if newShape has already been done
-			for _, dupe := range dupeChecker {
-				if dupe.Eq(newShape) {
-					continue neighborLoop
-				}
+		// check if newShape has already been done
+		for _, dupe := range it.dupeChecker {
+			if dupe.Eq(newShape) {
+				continue neighborLoop
 			}
-
-			heap.Push(&blobPQ, newShape)
-			dupeChecker = append(dupeChecker, newShape)
 		}
+
+		heap.Push(&it.blobPQ, newShape)
+		it.dupeChecker = append(it.dupeChecker, newShape)
 	}
 }
 
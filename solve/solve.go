@@ -1,89 +1,132 @@
 package solve
 
 import (
+	"context"
 	"fmt"
 
 	gs "github.com/deanveloper/gridspech-go"
 )
 
-// SolveAllTiles returns a channel which will return a TileSet of all tiles in g.
-func (g GridSolver) SolveAllTiles() <-chan gs.TileSet {
-	solutionIter := make(chan gs.TileSet)
-
-	go func() {
-		defer close(solutionIter)
-
-		for goalsAndDots := range MergeSolutionsIters(g.SolveGoals(), g.SolveDots()) {
-			newGrid := g.Clone()
-			newGrid.Grid.ApplyTileSet(goalsAndDots)
-			newGrid.UnknownTiles.RemoveAll(goalsAndDots.ToTileCoordSet())
-
-			for joinsSolution := range newGrid.SolveJoins() {
-				joinsSolved := newGrid.Clone()
-				joinsSolved.Grid.ApplyTileSet(joinsSolution)
-				joinsSolved.UnknownTiles.RemoveAll(joinsSolution.ToTileCoordSet())
-
-				for crownsSolution := range joinsSolved.SolveCrowns() {
-					crownsSolved := joinsSolved.Clone()
-					crownsSolved.Grid.ApplyTileSet(crownsSolution)
-					crownsSolved.UnknownTiles.RemoveAll(crownsSolution.ToTileCoordSet())
-
-					if crownsSolved.Grid.Valid() {
-						var merged gs.TileSet
-						merged.Merge(goalsAndDots)
-						merged.Merge(joinsSolution)
-						merged.Merge(crownsSolution)
-						solutionIter <- merged
-					}
-				}
+// TileSolver can be implemented by a gs.TileRule so that tiles with its type can be solved.
+type TileSolver interface {
+	// SolveTile returns a channel of solutions for t in g. Each solution should
+	// contain the tiles which t depends on. The channel should be closed once
+	// all solutions have been sent, or once ctx is cancelled.
+	SolveTile(ctx context.Context, g GridSolver, t gs.Tile) <-chan gs.Assignment
+}
+
+// Engine is a strategy for finding all solutions to a GridSolver, which can be set
+// as GridSolver.Engine to replace the default one.
+type Engine interface {
+	// SolveAll returns a channel of solutions for all tiles in g. The channel should
+	// be closed once all solutions have been sent, or once ctx is cancelled.
+	SolveAll(ctx context.Context, g GridSolver) <-chan gs.Assignment
+}
+
+// SolveAllTiles returns a channel which will return an Assignment of all tiles in g.
+func (g GridSolver) SolveAllTiles() <-chan gs.Assignment {
+	return g.SolveAllTilesContext(context.Background())
+}
+
+// SolveAllTilesContext is like SolveAllTiles, but all of the goroutines used to solve g
+// will stop, and the returned channel will be closed, once ctx is cancelled.
+func (g GridSolver) SolveAllTilesContext(ctx context.Context) <-chan gs.Assignment {
+	if g.Engine != nil && !g.Sorted {
+		return g.Engine.SolveAll(ctx, g)
+	}
+	return IterToChan(ctx, g.IterateAllTiles(ctx))
+}
+
+// IterateAllTiles is like SolveAllTilesContext, but returns an iterator. If g.Engine is set,
+// its solutions are read from a channel, and its goroutines stop once the iterator is closed.
+func (g GridSolver) IterateAllTiles(ctx context.Context) SolutionIterator {
+	if g.Sorted {
+		unsorted := g
+		unsorted.Sorted = false
+		return &sortedIter{in: unsorted.IterateAllTiles(ctx), key: g.coloring}
+	}
+	if g.Engine != nil {
+		return chanFuncToIter(ctx, func(ctx context.Context) <-chan gs.Assignment {
+			return g.Engine.SolveAll(ctx, g)
+		})
+	}
+
+	return g.iterateConstraints(ctx, g.constraints(), gs.Assignment{}, 0)
+}
+
+// coloring returns the colors of every tile in g.Grid after solution has been applied to it.
+func (g GridSolver) coloring(solution gs.Assignment) gs.Assignment {
+	colors := solution.Clone()
+	for _, col := range g.Grid.Tiles {
+		for _, tile := range col {
+			if !colors.Has(tile.Coord) {
+				colors.Set(tile.Coord, tile.Data.Color)
 			}
 		}
-	}()
-
-	return solutionIter
+	}
+	return colors
 }
 
 // SolveTiles returns a channel of possible solutions for the given tiles.
-func (g GridSolver) SolveTiles(tiles ...gs.TileCoord) <-chan gs.TileSet {
+func (g GridSolver) SolveTiles(tiles ...gs.TileCoord) <-chan gs.Assignment {
+	return g.SolveTilesContext(context.Background(), tiles...)
+}
+
+// SolveTilesContext is like SolveTiles, but stops once ctx is cancelled.
+func (g GridSolver) SolveTilesContext(ctx context.Context, tiles ...gs.TileCoord) <-chan gs.Assignment {
+	return IterToChan(ctx, g.IterateTiles(ctx, tiles...))
+}
+
+// IterateTiles is like SolveTilesContext, but returns an iterator.
+func (g GridSolver) IterateTiles(ctx context.Context, tiles ...gs.TileCoord) SolutionIterator {
 
 	if len(tiles) == 0 {
-		ch := make(chan gs.TileSet, 1)
-		ch <- gs.NewTileSet()
-		close(ch)
-		return ch
+		return sliceIter(gs.Assignment{})
 	}
 
-	tilesToSolutions := make([]<-chan gs.TileSet, len(tiles))
+	tilesToSolutions := make([]SolutionIterator, len(tiles))
 	for i, tile := range tiles {
-		tilesToSolutions[i] = g.solveTile(*g.Grid.TileAtCoord(tile))
+		tilesToSolutions[i] = g.iterateTile(ctx, *g.Grid.TileAtCoord(tile))
 	}
 
 	// now merge them all together
 	for i := 1; i < len(tiles); i++ {
-		mergedIter := MergeSolutionsIters(tilesToSolutions[i-1], tilesToSolutions[i])
-		uniqueIter := filterUnique(mergedIter)
-		tilesToSolutions[i] = uniqueIter
+		mergedIter := g.merge(ctx, tilesToSolutions[i-1], tilesToSolutions[i])
+		tilesToSolutions[i] = filterUnique(ctx, mergedIter)
 	}
 
 	return tilesToSolutions[len(tilesToSolutions)-1]
 }
 
-func (g GridSolver) solveTile(t gs.Tile) <-chan gs.TileSet {
+func (g GridSolver) iterateTile(ctx context.Context, t gs.Tile) SolutionIterator {
 	switch t.Data.Type {
 	case gs.TypeHole, gs.TypeBlank:
-		ch := make(chan gs.TileSet, 1)
-		ch <- gs.NewTileSet()
-		close(ch)
-		return ch
+		return sliceIter(gs.Assignment{})
 	case gs.TypeGoal:
-		return filterHasTile(g.SolveGoals(), t.Coord)
+		return filterHasTile(ctx, g.IterateGoals(ctx), t.Coord)
 	case gs.TypeCrown:
-		return g.SolveCrown(t.Coord)
+		return g.IterateCrown(ctx, t.Coord)
 	case gs.TypeDot1, gs.TypeDot2, gs.TypeDot3:
-		return g.SolveDot(t)
+		return g.IterateDot(ctx, t)
 	case gs.TypeJoin1, gs.TypeJoin2:
-		return g.SolveJoin(t)
+		return g.IterateJoin(ctx, t)
 	default:
+		if solver, ok := t.Data.Type.Rule().(TileSolver); ok {
+			return chanFuncToIter(ctx, func(ctx context.Context) <-chan gs.Assignment {
+				return solver.SolveTile(ctx, g, t)
+			})
+		}
 		panic(fmt.Sprintf("invalid type %v", t.Data.Type))
 	}
 }
+
+// iterateCustomTiles solves all tiles whose types were registered outside of gridspech
+// and can be solved with a TileSolver.
+func (g GridSolver) iterateCustomTiles(ctx context.Context) SolutionIterator {
+	customTiles := g.Grid.TilesWith(func(o gs.Tile) bool {
+		_, ok := o.Data.Type.Rule().(TileSolver)
+		return !o.Data.Type.Builtin() && ok
+	})
+
+	return g.IterateTiles(ctx, customTiles.ToTileCoordSet().Slice()...)
+}
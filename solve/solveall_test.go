@@ -1,8 +1,11 @@
 package solve_test
 
 import (
+	"sort"
+	"strings"
 	"testing"
 
+	gs "github.com/deanveloper/gridspech-go"
 	"github.com/deanveloper/gridspech-go/solve"
 )
 
@@ -28,3 +31,40 @@ func TestSolveAllTiles_levelF10(t *testing.T) {
 
 	testSolveAllTilesAbstract(t, level, solutions, 2)
 }
+
+func TestSolveAllTiles_sorted(t *testing.T) {
+	const level = `
+	0e  0  0  0e
+	0   0  0  0
+	0   0  0  0
+	`
+	coloringsOf := func(solver solve.GridSolver) []string {
+		var colorings []string
+		for solution := range solver.SolveAllTiles() {
+			grid := solver.Grid.Clone()
+			solution.Apply(grid)
+			colorings = append(colorings, grid.String())
+		}
+		return colorings
+	}
+
+	solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+	solver.Sorted = true
+	satSolver := solver.Clone()
+	satSolver.Engine = solve.SATEngine{}
+
+	for _, s := range []solve.GridSolver{solver, satSolver} {
+		expected := coloringsOf(s)
+		if len(expected) < 2 {
+			t.Fatalf("expected multiple solutions, got %d", len(expected))
+		}
+		if !sort.StringsAreSorted(expected) {
+			t.Errorf("expected solutions to be in reading order, got\n%v", strings.Join(expected, "\n\n"))
+		}
+		for i := 0; i < 5; i++ {
+			if actual := coloringsOf(s); strings.Join(actual, "\n\n") != strings.Join(expected, "\n\n") {
+				t.Fatalf("expected the same order every time, got\n%v\n\nand\n%v", strings.Join(expected, "\n\n"), strings.Join(actual, "\n\n"))
+			}
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package solve
+
+import (
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// TileStatus is whether a tile's rule can be followed once the unknown tiles of a GridSolver are colored.
+type TileStatus int
+
+const (
+	// TileUndetermined means that the tile may or may not be valid, depending on how
+	// the unknown tiles are colored.
+	TileUndetermined TileStatus = iota
+	// TileSatisfied means that the tile is valid however the unknown tiles are colored.
+	TileSatisfied
+	// TileViolated means that the tile is invalid however the unknown tiles are colored.
+	TileViolated
+)
+
+func (s TileStatus) String() string {
+	switch s {
+	case TileUndetermined:
+		return "undetermined"
+	case TileSatisfied:
+		return "satisfied"
+	case TileViolated:
+		return "violated"
+	default:
+		return "unknown"
+	}
+}
+
+// TileStatus returns whether the tile at coord is valid in g.Grid however the tiles in g.UnknownTiles
+// are colored. Unlike gs.Grid.ValidTile, it can be used on grids which are only partially solved.
+//
+// TileStatus only does a quick check, so it may return TileUndetermined for tiles whose status
+// could be found with a search. Tiles with types that are not built in are always undetermined
+// until there are no unknown tiles left. Goals which were paired with PairGoals are violated
+// if they cannot be connected to their partner.
+func (g GridSolver) TileStatus(coord gs.TileCoord) TileStatus {
+	t := *g.Grid.TileAtCoord(coord)
+	switch t.Data.Type {
+	case gs.TypeHole, gs.TypeBlank:
+		return TileSatisfied
+	case gs.TypeGoal:
+		return g.goalStatus(t)
+	case gs.TypeCrown:
+		return g.crownStatus(t)
+	case gs.TypeDot1:
+		return g.dotStatus(t, 1)
+	case gs.TypeDot2:
+		return g.dotStatus(t, 2)
+	case gs.TypeDot3:
+		return g.dotStatus(t, 3)
+	case gs.TypeJoin1:
+		return g.joinStatus(t, 1)
+	case gs.TypeJoin2:
+		return g.joinStatus(t, 2)
+	}
+
+	if g.UnknownTiles.Len() > 0 {
+		return TileUndetermined
+	}
+	return g.exactStatus(t)
+}
+
+// hasViolatedTile returns if any tile in g is violated however its unknown tiles are colored.
+func (g GridSolver) hasViolatedTile() bool {
+	for _, col := range g.Grid.Tiles {
+		for _, tile := range col {
+			if g.TileStatus(tile.Coord) == TileViolated {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exactStatus returns the status of t, which must not depend on any unknown tiles.
+func (g GridSolver) exactStatus(t gs.Tile) TileStatus {
+	if g.Grid.ValidTile(t.Coord) {
+		return TileSatisfied
+	}
+	return TileViolated
+}
+
+// dot tiles must touch exactly n tiles which are colored.
+func (g GridSolver) dotStatus(t gs.Tile, n int) TileStatus {
+	var colored, unknown int
+	for _, neighbor := range g.Grid.NeighborSlice(t.Coord) {
+		if g.UnknownTiles.Has(neighbor.Coord) {
+			unknown++
+		} else if neighbor.Data.Color != gs.ColorNone {
+			colored++
+		}
+	}
+	if g.Grid.MaxColors < 2 {
+		unknown = 0
+	}
+
+	switch {
+	case colored > n || colored+unknown < n:
+		return TileViolated
+	case unknown == 0:
+		return TileSatisfied
+	default:
+		return TileUndetermined
+	}
+}
+
+// the blob of a goal must be a direct path to exactly one other goal.
+func (g GridSolver) goalStatus(t gs.Tile) TileStatus {
+	if g.UnknownTiles.Has(t.Coord) {
+		return TileUndetermined
+	}
+
+	blob := g.knownBlob(t)
+	var goals int
+	for _, coord := range blob.Slice() {
+		tile := g.Grid.TileAtCoord(coord)
+		want := 2
+		if tile.Data.Type == gs.TypeGoal {
+			goals++
+			want = 1
+		}
+
+		// known neighbors with the same color can only be added to by unknown neighbors, so
+		// more than want is final, and the unknown neighbors are the only way to reach want
+		var same, unknown int
+		for _, neighbor := range g.Grid.NeighborSlice(coord) {
+			if g.UnknownTiles.Has(neighbor.Coord) {
+				unknown++
+			} else if neighbor.Data.Color == t.Data.Color {
+				same++
+			}
+		}
+		if same > want || same+unknown < want {
+			return TileViolated
+		}
+	}
+	if goals > 2 {
+		return TileViolated
+	}
+
+	possible := g.possibleBlob(t)
+	var reachableGoals int
+	for _, coord := range possible.Slice() {
+		if g.Grid.TileAtCoord(coord).Data.Type == gs.TypeGoal {
+			reachableGoals++
+		}
+	}
+	if reachableGoals < 2 {
+		return TileViolated
+	}
+
+	partner, paired := g.goalPartner(t.Coord)
+	if paired && !possible.Has(partner) {
+		return TileViolated
+	}
+	if g.isClosed(blob) {
+		if paired && !blob.Has(partner) {
+			return TileViolated
+		}
+		return g.exactStatus(t)
+	}
+	return TileUndetermined
+}
+
+// join tiles must have exactly n other non-blank tiles in their blob.
+func (g GridSolver) joinStatus(t gs.Tile, n int) TileStatus {
+	if g.UnknownTiles.Has(t.Coord) {
+		return TileUndetermined
+	}
+
+	blob := g.knownBlob(t)
+	if numSpecial(g.Grid, blob) > n+1 {
+		return TileViolated
+	}
+	if numSpecial(g.Grid, g.possibleBlob(t)) < n+1 {
+		return TileViolated
+	}
+	if g.isClosed(blob) {
+		return g.exactStatus(t)
+	}
+	return TileUndetermined
+}
+
+// crown tiles may not share their blob with another crown, and every tile with the
+// same color as a crown must be in the blob of a crown.
+func (g GridSolver) crownStatus(t gs.Tile) TileStatus {
+	if g.UnknownTiles.Has(t.Coord) {
+		return TileUndetermined
+	}
+
+	for _, coord := range g.knownBlob(t).Slice() {
+		if coord != t.Coord && g.Grid.TileAtCoord(coord).Data.Type == gs.TypeCrown {
+			return TileViolated
+		}
+	}
+
+	// every known tile with this color must be able to join the blob of some crown
+	var crowns []gs.TileCoord
+	for _, col := range g.Grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type == gs.TypeCrown && (tile.Data.Color == t.Data.Color || g.UnknownTiles.Has(tile.Coord)) {
+				crowns = append(crowns, tile.Coord)
+			}
+		}
+	}
+	covered := g.reachable(crowns, func(o gs.Tile) bool {
+		return o.Data.Color == t.Data.Color || g.UnknownTiles.Has(o.Coord)
+	})
+	for _, col := range g.Grid.Tiles {
+		for _, tile := range col {
+			if tile.Data.Type != gs.TypeHole && tile.Data.Color == t.Data.Color && !g.UnknownTiles.Has(tile.Coord) && !covered.Has(tile.Coord) {
+				return TileViolated
+			}
+		}
+	}
+
+	if g.UnknownTiles.Len() == 0 {
+		return g.exactStatus(t)
+	}
+	return TileUndetermined
+}
+
+// knownBlob returns the tiles which are definitely in the blob of t, which must not be unknown.
+func (g GridSolver) knownBlob(t gs.Tile) gs.TileCoordSet {
+	return g.reachable([]gs.TileCoord{t.Coord}, func(o gs.Tile) bool {
+		return o.Data.Color == t.Data.Color && !g.UnknownTiles.Has(o.Coord)
+	})
+}
+
+// possibleBlob returns the tiles which could be in the blob of t once the unknown tiles are colored.
+func (g GridSolver) possibleBlob(t gs.Tile) gs.TileCoordSet {
+	return g.reachable([]gs.TileCoord{t.Coord}, func(o gs.Tile) bool {
+		return o.Data.Color == t.Data.Color || g.UnknownTiles.Has(o.Coord)
+	})
+}
+
+// isClosed returns if none of the tiles in blob have unknown neighbors, so that coloring
+// the unknown tiles cannot change the blob or the neighbors of its tiles.
+func (g GridSolver) isClosed(blob gs.TileCoordSet) bool {
+	for _, coord := range blob.Slice() {
+		for _, neighbor := range g.Grid.NeighborSlice(coord) {
+			if g.UnknownTiles.Has(neighbor.Coord) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// reachable returns the tiles which can be reached from start while only passing through
+// tiles such that pred returns true. Tiles in start are always included.
+func (g GridSolver) reachable(start []gs.TileCoord, pred func(o gs.Tile) bool) gs.TileCoordSet {
+	seen := gs.NewTileCoordSet(start...)
+	stack := append([]gs.TileCoord(nil), start...)
+	for len(stack) > 0 {
+		coord := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, neighbor := range g.Grid.NeighborSlice(coord) {
+			if !seen.Has(neighbor.Coord) && pred(neighbor) {
+				seen.Add(neighbor.Coord)
+				stack = append(stack, neighbor.Coord)
+			}
+		}
+	}
+	return seen
+}
+
+// numSpecial returns the number of tiles in blob which are not blank.
+func numSpecial(g gs.Grid, blob gs.TileCoordSet) int {
+	var special int
+	for _, coord := range blob.Slice() {
+		if tile := g.TileAtCoord(coord); tile.Data.Type != gs.TypeHole && tile.Data.Type != gs.TypeBlank {
+			special++
+		}
+	}
+	return special
+}
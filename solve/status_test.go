@@ -0,0 +1,106 @@
+package solve_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+	"github.com/deanveloper/gridspech-go/solve"
+)
+
+func TestTileStatus(t *testing.T) {
+	tests := []struct {
+		level    string
+		unknown  []gs.TileCoord
+		coord    gs.TileCoord
+		expected solve.TileStatus
+	}{
+		{"0  0m2  1", nil, gs.TileCoord{X: 1}, solve.TileViolated},
+		{"1  0m2  1", nil, gs.TileCoord{X: 1}, solve.TileSatisfied},
+		{"0  0m2  1", []gs.TileCoord{{X: 0}}, gs.TileCoord{X: 1}, solve.TileUndetermined},
+		{"1  0m1  1", nil, gs.TileCoord{X: 1}, solve.TileViolated},
+		{"1  0m1  1", []gs.TileCoord{{X: 0}}, gs.TileCoord{X: 1}, solve.TileUndetermined},
+
+		{"0e  0  0e", nil, gs.TileCoord{}, solve.TileSatisfied},
+		{"0e  1  0e", nil, gs.TileCoord{}, solve.TileViolated},
+		{"0e  1  0e", []gs.TileCoord{{X: 1}}, gs.TileCoord{}, solve.TileUndetermined},
+		{"0e  1  0e", []gs.TileCoord{{X: 0}}, gs.TileCoord{}, solve.TileUndetermined},
+		{"0e  1  0  0e", []gs.TileCoord{{X: 2}}, gs.TileCoord{}, solve.TileViolated},
+		{"0e  0  0e  0  0e", []gs.TileCoord{{X: 3}}, gs.TileCoord{}, solve.TileUndetermined},
+		{"0e  0  0e  0  0e", nil, gs.TileCoord{}, solve.TileViolated},
+
+		{"0j1  0  0e  1", nil, gs.TileCoord{}, solve.TileSatisfied},
+		{"0j1  0  0e  1", []gs.TileCoord{{X: 3}}, gs.TileCoord{}, solve.TileUndetermined},
+		{"0j1  0  0e  0e", []gs.TileCoord{{X: 1}}, gs.TileCoord{}, solve.TileUndetermined},
+		{"0j1  0  0e  0e", nil, gs.TileCoord{}, solve.TileViolated},
+		{"0j1  1  0e", nil, gs.TileCoord{}, solve.TileViolated},
+		{"0j1  1  0e", []gs.TileCoord{{X: 1}}, gs.TileCoord{}, solve.TileUndetermined},
+
+		{"0k  0  1k", nil, gs.TileCoord{}, solve.TileSatisfied},
+		{"0k  0  0k", nil, gs.TileCoord{}, solve.TileViolated},
+		{"0k  0  0k", []gs.TileCoord{{X: 1}}, gs.TileCoord{}, solve.TileUndetermined},
+		{"0k  1  0", nil, gs.TileCoord{}, solve.TileViolated},
+		{"0k  1  0", []gs.TileCoord{{X: 1}}, gs.TileCoord{}, solve.TileUndetermined},
+
+		{"0  0k  0", nil, gs.TileCoord{}, solve.TileSatisfied},
+	}
+
+	for _, test := range tests {
+		solver := solve.NewGridSolver(gs.MakeGridFromString(test.level, 2))
+		solver.UnknownTiles = gs.NewTileCoordSet(test.unknown...)
+		if status := solver.TileStatus(test.coord); status != test.expected {
+			t.Errorf("level %q with unknown tiles %v: expected %v at %v to be %v, got %v",
+				test.level, test.unknown, solver.Grid.TileAtCoord(test.coord).Data.Type, test.coord, test.expected, status)
+		}
+	}
+}
+
+// TestTileStatus_random checks that TileStatus agrees with gs.Grid.ValidTile for every
+// way that the unknown tiles of random levels can be colored.
+func TestTileStatus_random(t *testing.T) {
+	types := []string{"", "", "", "e", "e", "k", "m1", "m2", "j1", "j2"}
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 300; i++ {
+		var rows []string
+		for y := 0; y < 2; y++ {
+			var row []string
+			for x := 0; x < 3; x++ {
+				row = append(row, string(rune('0'+rng.Intn(2)))+types[rng.Intn(len(types))])
+			}
+			rows = append(rows, strings.Join(row, " "))
+		}
+		level := strings.Join(rows, "\n")
+
+		solver := solve.NewGridSolver(gs.MakeGridFromString(level, 2))
+		var unknown []gs.TileCoord
+		for _, coord := range solver.UnknownTiles.Slice() {
+			if rng.Intn(2) == 0 {
+				unknown = append(unknown, coord)
+			}
+		}
+		solver.UnknownTiles = gs.NewTileCoordSet(unknown...)
+
+		for x := 0; x < solver.Grid.Width(); x++ {
+			for y := 0; y < solver.Grid.Height(); y++ {
+				coord := gs.TileCoord{X: x, Y: y}
+				status := solver.TileStatus(coord)
+				if status == solve.TileUndetermined {
+					continue
+				}
+
+				for coloring := 0; coloring < 1<<len(unknown); coloring++ {
+					grid := solver.Grid.Clone()
+					for i, unknownCoord := range unknown {
+						grid.TileAtCoord(unknownCoord).Data.Color = gs.TileColor(coloring >> i & 1)
+					}
+					if valid := grid.ValidTile(coord); valid != (status == solve.TileSatisfied) {
+						t.Fatalf("expected %v at %v to be %v with unknown tiles %v, but it is valid=%v in\n%v",
+							grid.TileAtCoord(coord).Data.Type, coord, status, unknown, valid, grid)
+					}
+				}
+			}
+		}
+	}
+}
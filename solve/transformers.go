@@ -1,212 +1,151 @@
 package solve
 
-import gs "github.com/deanveloper/gridspech-go"
+import (
+	"context"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
 
 // MergeSolutionsIters makes pairs of solutions from sols1 and sols2 into
 // a single solution, then returns a channel of the merged pairs of solutions.
 //
-// A solution pair will only be sent if any tiles which appear in both solutions are equal.
-func MergeSolutionsIters(sols1, sols2 <-chan gs.TileSet) <-chan gs.TileSet {
-	iter := make(chan gs.TileSet, 20)
-
-	go func() {
-		// read sols2 into a slice
-		var sols2slice []gs.TileSet
-		for sol2 := range sols2 {
-			sols2slice = append(sols2slice, sol2)
-		}
+// A solution pair will only be sent if they do not assign different colors to the same tile.
+func MergeSolutionsIters(sols1, sols2 <-chan gs.Assignment) <-chan gs.Assignment {
+	return MergeSolutionsItersContext(context.Background(), sols1, sols2)
+}
 
-		// merge
-		for sol1 := range sols1 {
-
-		nextSolution:
-			for _, sol2 := range sols2slice {
-				var merged gs.TileSet
-
-				// do not merge if they have any tiles with unmatched colors
-				for _, t1 := range sol1.Slice() {
-					for _, t2 := range sol2.Slice() {
-						if t1.Coord == t2.Coord && t1.Data != t2.Data {
-							continue nextSolution
-						}
-					}
-				}
-
-				merged.Merge(sol1)
-				merged.Merge(sol2)
-				iter <- merged
-			}
-		}
-		close(iter)
-	}()
+// MergeSolutionsItersContext is like MergeSolutionsIters, but stops once ctx is cancelled.
+// sols1 and sols2 should also stop once ctx is cancelled.
+func MergeSolutionsItersContext(ctx context.Context, sols1, sols2 <-chan gs.Assignment) <-chan gs.Assignment {
+	return IterToChan(ctx, MergeSolutions(ctx, ChanToIter(sols1), ChanToIter(sols2)))
+}
 
-	return iter
+// filterUnique removes solutions from in which are equal to one it has already returned.
+func filterUnique(ctx context.Context, in SolutionIterator) SolutionIterator {
+	return filterIter(ctx, in, newDeduper().firstTime)
 }
 
-func filterUnique(in <-chan gs.TileSet) <-chan gs.TileSet {
-	filtered := make(chan gs.TileSet, 20)
-
-	go func() {
-		var alreadySeen []gs.TileSet
-		for newSolution := range in {
-			unique := true
-			for _, seen := range alreadySeen {
-				if newSolution.Eq(seen) {
-					unique = false
-					break
-				}
-			}
-			if unique {
-				alreadySeen = append(alreadySeen, newSolution)
-				filtered <- newSolution
-			}
-		}
-		close(filtered)
-	}()
+// deduper remembers the fingerprints of solutions it has seen.
+type deduper map[gs.Fingerprint]struct{}
 
-	return filtered
+func newDeduper() deduper {
+	return make(deduper)
+}
+
+// firstTime returns true if solution has not been seen by d before.
+func (d deduper) firstTime(solution gs.Assignment) bool {
+	fp := solution.Fingerprint()
+	if _, ok := d[fp]; ok {
+		return false
+	}
+	d[fp] = struct{}{}
+	return true
 }
 
 func filterValid(
+	ctx context.Context,
 	g GridSolver,
 	tilesToValidate []gs.Tile,
-	current gs.Tile,
-	sols <-chan gs.TileSet,
-) <-chan gs.TileSet {
-	filtered := make(chan gs.TileSet, 20)
-
-	go func() {
-		defer close(filtered)
-		for solution := range sols {
-			newBase := g.Grid.Clone()
-			newBase.ApplyTileSet(solution)
-
-			allValid := true
-			for _, tile := range tilesToValidate {
-				if !newBase.ValidTile(tile.Coord) {
-					allValid = false
-					break
-				}
-			}
-			if allValid {
-				filtered <- solution
+	sols SolutionIterator,
+) SolutionIterator {
+	return filterIter(ctx, sols, func(solution gs.Assignment) bool {
+		newBase := g.Grid.Clone()
+		solution.Apply(newBase)
+
+		for _, tile := range tilesToValidate {
+			if !newBase.ValidTile(tile.Coord) {
+				return false
 			}
 		}
-	}()
-
-	return filtered
+		return true
+	})
 }
 
-func filterHasTile(in <-chan gs.TileSet, coord gs.TileCoord) <-chan gs.TileSet {
-	filtered := make(chan gs.TileSet, 20)
+func filterHasTile(ctx context.Context, in SolutionIterator, coord gs.TileCoord) SolutionIterator {
+	return filterIter(ctx, in, func(solution gs.Assignment) bool {
+		return solution.ToTileCoordSet().Has(coord)
+	})
+}
 
-	go func() {
-		defer close(filtered)
-		for solution := range in {
-			if solution.ToTileCoordSet().Has(coord) {
-				filtered <- solution
+// filterAllowed removes the solutions which give a tile a color that is forbidden by g.Forbidden.
+func filterAllowed(ctx context.Context, g GridSolver, in SolutionIterator) SolutionIterator {
+	if len(g.Forbidden) == 0 {
+		return in
+	}
+	return filterIter(ctx, in, func(solution gs.Assignment) bool {
+		for coord, colors := range g.Forbidden {
+			if color, ok := solution.Get(coord); ok && colors.Has(color) {
+				return false
 			}
 		}
-	}()
-
-	return filtered
+		return true
+	})
 }
 
-func decorateSetBorder(g GridSolver, shapeColor gs.TileColor, tileSet gs.TileSet) <-chan gs.TileSet {
-	iter := make(chan gs.TileSet)
-	go func() {
-		defer close(iter)
-
-		var unknownNeighbors []gs.Tile
-		for tile := range tileSet.Iter() {
-			neighboringUnknowns := g.Grid.NeighborSetWith(tile.Coord, func(o gs.Tile) bool {
-				return g.UnknownTiles.Has(o.Coord) &&
-					!tileSet.ToTileCoordSet().Has(o.Coord)
-			})
-			unknownNeighbors = append(unknownNeighbors, neighboringUnknowns.Slice()...)
+func decorateSetBorder(ctx context.Context, g GridSolver, shapeColor gs.TileColor, shape gs.Assignment) SolutionIterator {
+	shapeCoords := shape.ToTileCoordSet()
+	var unknownNeighbors gs.TileCoordSet
+	for _, coord := range shape.Coords() {
+		neighboringUnknowns := g.Grid.NeighborSetWith(coord, func(o gs.Tile) bool {
+			return g.UnknownTiles.Has(o.Coord) && !shapeCoords.Has(o.Coord)
+		})
+		unknownNeighbors.Merge(neighboringUnknowns.ToTileCoordSet())
+	}
+	border := unknownNeighbors.Slice()
+
+	perms := newPermutationIter(g.Grid.MaxColors-1, len(border))
+	return iterFunc(func() (gs.Assignment, bool) {
+		permutation, ok := perms.next()
+		if !ok || ctx.Err() != nil {
+			return gs.Assignment{}, false
 		}
 
-		for permutation := range Permutation(g.Grid.MaxColors-1, len(unknownNeighbors)) {
-			var setWithDecoration gs.TileSet
-			setWithDecoration.Merge(tileSet)
-			for i, unknown := range unknownNeighbors {
-				color := permutation[i]
-				if color >= int(shapeColor) {
-					color++
-				}
-				unknown.Data.Color = gs.TileColor(color)
-				setWithDecoration.Add(unknown)
+		setWithDecoration := shape.Clone()
+		for i, coord := range border {
+			color := permutation[i]
+			if color >= int(shapeColor) {
+				color++
 			}
-			iter <- setWithDecoration
+			setWithDecoration.Set(coord, gs.TileColor(color))
 		}
-	}()
-	return iter
+		return setWithDecoration, true
+	})
 }
 
-func decorateSetIterBorders(g GridSolver, shapeColor gs.TileColor, tileSets <-chan gs.TileSet) <-chan gs.TileSet {
-	iter := make(chan gs.TileSet)
-	go func() {
-		defer close(iter)
-		for tileSet := range tileSets {
-
-			var unknownNeighbors []gs.Tile
-			for tile := range tileSet.Iter() {
-				neighboringUnknowns := g.Grid.NeighborSetWith(tile.Coord, func(o gs.Tile) bool {
-					return g.UnknownTiles.Has(o.Coord) &&
-						!tileSet.ToTileCoordSet().Has(o.Coord)
-				})
-				unknownNeighbors = append(unknownNeighbors, neighboringUnknowns.Slice()...)
-			}
+func decorateSetIterBorders(ctx context.Context, g GridSolver, shapeColor gs.TileColor, shapes SolutionIterator) SolutionIterator {
+	return flatMap(ctx, shapes, func(shape gs.Assignment) SolutionIterator {
+		return decorateSetBorder(ctx, g, shapeColor, shape)
+	})
+}
 
-			for permutation := range Permutation(g.Grid.MaxColors-1, len(unknownNeighbors)) {
-				var setWithDecoration gs.TileSet
-				setWithDecoration.Merge(tileSet)
-				for i, unknown := range unknownNeighbors {
-					color := permutation[i]
-					if color >= int(shapeColor) {
-						color++
-					}
-					unknown.Data.Color = gs.TileColor(color)
-					setWithDecoration.Add(unknown)
-				}
-				iter <- setWithDecoration
-			}
-		}
-	}()
-	return iter
+// assignColor assigns color to each coordinate in coords.
+func assignColor(coords gs.TileCoordSet, color gs.TileColor) gs.Assignment {
+	var result gs.Assignment
+	for _, coord := range coords.Slice() {
+		result.Set(coord, color)
+	}
+	return result
 }
 
-func mergeSolutionsSlices(sols1, sols2 []gs.TileSet) []gs.TileSet {
-	var result []gs.TileSet
+func mergeSolutionsSlices(sols1, sols2 []gs.Assignment) []gs.Assignment {
+	var result []gs.Assignment
 	for _, sol1 := range sols1 {
-	nextSolution:
 		for _, sol2 := range sols2 {
-
-			// do not merge if they have any tiles with unmatched colors
-			for _, t1 := range sol1.Slice() {
-				for _, t2 := range sol2.Slice() {
-					if t1.Coord == t2.Coord && t1.Data.Color != t2.Data.Color {
-						continue nextSolution
-					}
-				}
+			if merged, ok := sol1.Merged(sol2); ok {
+				result = append(result, merged)
 			}
-
-			var merged gs.TileSet
-			merged.Merge(sol1)
-			merged.Merge(sol2)
-			result = append(result, merged)
 		}
 	}
 	return result
 }
 
-func removeIfInvalid(g GridSolver, tilesToValidate []gs.TileCoord, in []gs.TileSet) []gs.TileSet {
-	var validSolutions []gs.TileSet
+func removeIfInvalid(g GridSolver, tilesToValidate []gs.TileCoord, in []gs.Assignment) []gs.Assignment {
+	var validSolutions []gs.Assignment
 
 	base := g.Grid
 	for _, solution := range in {
 		newBase := base.Clone()
-		newBase.ApplyTileSet(solution)
+		solution.Apply(newBase)
 
 		allValid := true
 		for _, coord := range tilesToValidate {
@@ -217,24 +156,20 @@ func removeIfInvalid(g GridSolver, tilesToValidate []gs.TileCoord, in []gs.TileS
 		}
 		if allValid {
 			validSolutions = append(validSolutions, solution)
+		} else {
+			g.observe(EventInvalidCandidate)
 		}
 	}
 
 	return validSolutions
 }
 
-func removeIfNonUnique(in []gs.TileSet) []gs.TileSet {
-	var filtered []gs.TileSet
+func removeIfNonUnique(in []gs.Assignment) []gs.Assignment {
+	var filtered []gs.Assignment
 
+	seen := newDeduper()
 	for _, solution := range in {
-		unique := true
-		for _, seen := range filtered {
-			if solution.Eq(seen) {
-				unique = false
-				break
-			}
-		}
-		if unique {
+		if seen.firstTime(solution) {
 			filtered = append(filtered, solution)
 		}
 	}
@@ -55,7 +55,7 @@ func tryTest() {
 	ch := solve.NewGridSolver(grid).SolveAllTiles()
 	for solved := range ch {
 		newGrid := grid.Clone()
-		newGrid.ApplyTileSet(solved)
+		solved.Apply(newGrid)
 		fmt.Println("======")
 		fmt.Println(newGrid)
 	}
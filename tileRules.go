@@ -0,0 +1,122 @@
+package gridspech
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TileRule describes the behavior of a TileType. Rules for the built-in types are
+// registered by this package, and new types can be added with RegisterTileRule.
+//
+// A TileRule may also implement solve.TileSolver so that the solver is able to
+// find solutions for tiles of its type.
+type TileRule interface {
+	// Name is the name of the type, which is returned by TileType.String. ie "Dot2".
+	Name() string
+
+	// Token is the text which represents the type in a serialized level. ie "m2".
+	// The token for TypeBlank is empty, and the token for TypeHole is "_".
+	Token() string
+
+	// Violations returns the reasons that t is invalid in g, or nil if t is valid.
+	Violations(g Grid, t Tile) []Violation
+}
+
+type builtinRule struct {
+	name, token string
+	violations  func(g Grid, t Tile) []Violation
+}
+
+func (r builtinRule) Name() string                          { return r.name }
+func (r builtinRule) Token() string                         { return r.token }
+func (r builtinRule) Violations(g Grid, t Tile) []Violation { return r.violations(g, t) }
+
+func noViolations(g Grid, t Tile) []Violation { return nil }
+
+// tileRules is indexed by TileType.
+var tileRules = []TileRule{
+	TypeHole:  builtinRule{"_", "_", noViolations},
+	TypeBlank: builtinRule{"Blank", "", noViolations},
+	TypeGoal:  builtinRule{"Goal", "e", Grid.goalViolations},
+	TypeCrown: builtinRule{"Crown", "k", Grid.crownViolations},
+	TypeDot1:  builtinRule{"Dot1", "m1", func(g Grid, t Tile) []Violation { return g.dotViolations(t, 1) }},
+	TypeDot2:  builtinRule{"Dot2", "m2", func(g Grid, t Tile) []Violation { return g.dotViolations(t, 2) }},
+	TypeDot3:  builtinRule{"Dot3", "m3", func(g Grid, t Tile) []Violation { return g.dotViolations(t, 3) }},
+	TypeJoin1: builtinRule{"Join1", "j1", func(g Grid, t Tile) []Violation { return g.joinViolations(t, 1) }},
+	TypeJoin2: builtinRule{"Join2", "j2", func(g Grid, t Tile) []Violation { return g.joinViolations(t, 2) }},
+}
+
+// RegisterTileRule adds a new TileType which behaves according to rule, and returns it.
+// It panics if the rule's token is malformed or already in use.
+//
+// Tokens may not start with a digit, or contain whitespace or any of "_/<^>". They may contain
+// "v", unless the token is another token followed by only v's (such as "ev" or "v"), since
+// the v's would also be read as a south arrow after the other token.
+//
+// RegisterTileRule is meant to be called from init functions, and is not safe to call
+// concurrently with anything else in this package.
+func RegisterTileRule(rule TileRule) TileType {
+	token := rule.Token()
+	if token == "" || (token[0] >= '0' && token[0] <= '9') || strings.ContainsAny(token, "_/<^> \t\r\n") {
+		panic(fmt.Sprintf("gridspech: invalid token %q for tile type %s", token, rule.Name()))
+	}
+	for i, existing := range tileRules {
+		if TileType(i) == TypeHole {
+			continue
+		}
+		if existing.Token() == token {
+			panic(fmt.Sprintf("gridspech: token %q for tile type %s is already used by %s", token, rule.Name(), existing.Name()))
+		}
+		if southArrowsAfter(existing.Token(), token) || southArrowsAfter(token, existing.Token()) {
+			panic(fmt.Sprintf("gridspech: token %q for tile type %s could be read as %s with a south arrow", token, rule.Name(), existing.Name()))
+		}
+	}
+	if len(tileRules) > 255 {
+		panic("gridspech: too many tile types")
+	}
+
+	tileRules = append(tileRules, rule)
+	return TileType(len(tileRules) - 1)
+}
+
+// southArrowsAfter returns if b is a followed by one or more v's.
+func southArrowsAfter(a, b string) bool {
+	return len(b) > len(a) && strings.HasPrefix(b, a) && strings.Trim(b[len(a):], "v") == ""
+}
+
+// Rule returns the TileRule for typ, or nil if typ has not been registered.
+func (typ TileType) Rule() TileRule {
+	if int(typ) >= len(tileRules) {
+		return nil
+	}
+	return tileRules[typ]
+}
+
+// Builtin returns if typ is one of the types defined by this package.
+func (typ TileType) Builtin() bool {
+	return typ <= TypeJoin2
+}
+
+func (typ TileType) String() string {
+	if rule := typ.Rule(); rule != nil {
+		return rule.Name()
+	}
+	return "TileType(" + strconv.Itoa(int(typ)) + ")"
+}
+
+// typeForToken returns the type whose token is the longest prefix of s,
+// as well as the length of the token. If no token prefixes s, TypeBlank is returned.
+func typeForToken(s string) (TileType, int) {
+	typ, length := TypeBlank, 0
+	for i, rule := range tileRules {
+		if TileType(i) == TypeHole {
+			continue
+		}
+		token := rule.Token()
+		if len(token) > length && strings.HasPrefix(s, token) {
+			typ, length = TileType(i), len(token)
+		}
+	}
+	return typ, length
+}
@@ -0,0 +1,96 @@
+package gridspech_test
+
+import (
+	"testing"
+
+	gs "github.com/deanveloper/gridspech-go"
+)
+
+// lonelyRule is a tile which may not touch any tiles with its own color.
+type lonelyRule struct{}
+
+func (lonelyRule) Name() string  { return "Lonely" }
+func (lonelyRule) Token() string { return "lonely" }
+func (lonelyRule) Violations(g gs.Grid, t gs.Tile) []gs.Violation {
+	sameColor := g.NeighborSliceWith(t.Coord, func(o gs.Tile) bool {
+		return o.Data.Color == t.Data.Color
+	})
+	if len(sameColor) == 0 {
+		return nil
+	}
+	return []gs.Violation{{Tile: t, Kind: gs.ViolationRule, Message: "lonely tile has a friend"}}
+}
+
+var typeLonely = gs.RegisterTileRule(lonelyRule{})
+
+func TestRegisterTileRule(t *testing.T) {
+	const level = `
+	1/lonely^  1
+	1          0lonely
+	`
+	grid := gs.MakeGridFromString(level, 2)
+
+	if typeLonely.String() != "Lonely" || typeLonely.Builtin() {
+		t.Errorf("unexpected type %v (builtin: %v)", typeLonely, typeLonely.Builtin())
+	}
+
+	expected := gs.TileData{Type: typeLonely, Color: 1, Sticky: true, ArrowNorth: true}
+	if actual := grid.TileAt(0, 1).Data; actual != expected {
+		t.Errorf("\nexpected: %#v\ngot:      %#v", expected, actual)
+	}
+	if str := grid.TileAt(0, 1).Data.String(); str != "1/lonely^" {
+		t.Errorf("expected %q, got %q", "1/lonely^", str)
+	}
+
+	if grid.ValidTile(gs.TileCoord{X: 0, Y: 1}) {
+		t.Errorf("expected (0, 1) to be invalid")
+	}
+	if !grid.ValidTile(gs.TileCoord{X: 1, Y: 0}) {
+		t.Errorf("expected (1, 0) to be valid, got %v", grid.TileViolations(gs.TileCoord{X: 1, Y: 0}))
+	}
+}
+
+func TestRegisterTileRule_duplicateToken(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic when registering a duplicate token")
+		}
+	}()
+	gs.RegisterTileRule(lonelyRule{})
+}
+
+// tokenRule is a tile with any token, which has no rules.
+type tokenRule string
+
+func (r tokenRule) Name() string                                 { return "Token " + string(r) }
+func (r tokenRule) Token() string                                { return string(r) }
+func (tokenRule) Violations(g gs.Grid, t gs.Tile) []gs.Violation { return nil }
+
+var typeRV = gs.RegisterTileRule(tokenRule("rv"))
+
+func TestRegisterTileRule_v(t *testing.T) {
+	grid := gs.MakeGridFromString("0rv  0rvv  0ev", 2)
+
+	expected := []gs.TileData{
+		{Type: typeRV},
+		{Type: typeRV, ArrowSouth: true},
+		{Type: gs.TypeGoal, ArrowSouth: true},
+	}
+	for x, data := range expected {
+		if actual := grid.TileAt(x, 0).Data; actual != data {
+			t.Errorf("(%d, 0): expected %#v, got %#v", x, data, actual)
+		}
+	}
+
+	// each of these would be read as another token followed by a south arrow
+	for _, token := range []string{"v", "ev", "m1v", "rvv", "r"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic when registering %q", token)
+				}
+			}()
+			gs.RegisterTileRule(tokenRule(token))
+		}()
+	}
+}